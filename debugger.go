@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/gfx"
+	"github.com/bennicholls/tyumi/gfx/col"
 	"github.com/bennicholls/tyumi/gfx/ui"
 	"github.com/bennicholls/tyumi/input"
 	"github.com/bennicholls/tyumi/log"
@@ -32,7 +34,7 @@ type debugDialog struct {
 	commandDisplay ui.List
 
 	logPage    *ui.Page
-	logDisplay ui.List
+	logDisplay ui.MessageLog
 
 	commands map[string]debugCommand
 
@@ -66,13 +68,15 @@ func (d *debugDialog) Init() {
 	d.logDisplay.Init(d.logPage.Size().Shrink(0, 2), vec.ZERO_COORD, ui.BorderDepth)
 	d.logDisplay.EnableBorder()
 	d.logDisplay.SetCapacity(200)
-	d.logDisplay.OnItemInserted = func() { d.logDisplay.ScrollToBottom() }
+	d.logDisplay.SetCategoryColour(ui.MessageCategory(log.LVL_DEBUG), col.GREY)
+	d.logDisplay.SetCategoryColour(ui.MessageCategory(log.LVL_WARN), col.YELLOW)
+	d.logDisplay.SetCategoryColour(ui.MessageCategory(log.LVL_ERROR), col.RED)
 	d.logDisplay.AcceptInput = true
 	d.logPage.AddChild(&d.logDisplay)
 
 	// add already existing log messages
 	for _, entry := range log.GetLogs() {
-		d.logDisplay.InsertText(ui.ALIGN_LEFT, entry.SimpleString())
+		d.addToLogDisplay(entry)
 	}
 
 	d.keypressInputHandler = d.handleKeyEvent
@@ -149,7 +153,7 @@ func (d *debugDialog) addToCommandDisplay(command, text string) {
 }
 
 func (d *debugDialog) addToLogDisplay(entry log.Entry) {
-	d.logDisplay.InsertText(ui.ALIGN_LEFT, entry.SimpleString())
+	d.logDisplay.AddMessage(gfx.EscapeMarkup(strings.TrimSpace(entry.SimpleString())), ui.MessageCategory(entry.Level))
 }
 
 func RegisterDebugCommand(name, desc string, handler func(args []string) string) {
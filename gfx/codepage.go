@@ -0,0 +1,110 @@
+package gfx
+
+import (
+	"github.com/bennicholls/tyumi/log"
+)
+
+// CodePage maps between the 256 characters in a font and the unicode runes they represent. Text is stored as UTF-8
+// strings, so before drawing each rune has to be converted to a font index using the active code page. Runes with no
+// mapping are drawn as the ReplacementChar.
+type CodePage struct {
+	Name string
+
+	runes   [256]rune      // the rune represented by each character in the font
+	indices map[rune]uint8 // reverse lookup
+}
+
+// NewCodePage creates a code page from a table of runes, one for each character in the font. If multiple characters
+// map to the same rune, the first one is used when converting runes to characters.
+func NewCodePage(name string, runes [256]rune) (cp *CodePage) {
+	cp = &CodePage{Name: name, runes: runes, indices: make(map[rune]uint8, 256)}
+	for i, r := range runes {
+		if _, ok := cp.indices[r]; !ok {
+			cp.indices[r] = uint8(i)
+		}
+	}
+
+	return
+}
+
+// AddMapping maps an additional rune to a character in the font. Useful for adding fallbacks for characters the font
+// doesn't have, like drawing ő as ö.
+func (cp *CodePage) AddMapping(r rune, index uint8) {
+	cp.indices[r] = index
+}
+
+// Index returns the font character for the rune. ok is false if there is no mapping.
+func (cp *CodePage) Index(r rune) (index uint8, ok bool) {
+	index, ok = cp.indices[r]
+	return
+}
+
+// Rune returns the rune represented by the font character.
+func (cp *CodePage) Rune(index uint8) rune {
+	return cp.runes[index]
+}
+
+// CP437 is the classic IBM PC code page, and the layout of the default Tyumi fonts.
+var CP437 = NewCodePage("CP437", [256]rune([]rune(cp437Runes)))
+
+const cp437Runes = "\x00☺☻♥♦♣♠•◘○◙♂♀♪♫☼" +
+	"►◄↕‼¶§▬↨↑↓→←∟↔▲▼" +
+	" !\"#$%&'()*+,-./" +
+	"0123456789:;<=>?" +
+	"@ABCDEFGHIJKLMNO" +
+	"PQRSTUVWXYZ[\\]^_" +
+	"`abcdefghijklmno" +
+	"pqrstuvwxyz{|}~⌂" +
+	"ÇüéâäàåçêëèïîìÄÅ" +
+	"ÉæÆôöòûùÿÖÜ¢£¥₧ƒ" +
+	"áíóúñÑªº¿⌐¬½¼¡«»" +
+	"░▒▓│┤╡╢╖╕╣║╗╝╜╛┐" +
+	"└┴┬├─┼╞╟╚╔╩╦╠═╬╧" +
+	"╨╤╥╙╘╒╓╫╪┘┌█▄▌▐▀" +
+	"αßΓπΣσµτΦΘΩδ∞φε∩" +
+	"≡±≥≤⌠⌡÷≈°∙·√ⁿ²■\u00a0"
+
+// ReplacementChar is the font character drawn in place of runes the active code page can't map.
+var ReplacementChar uint8 = '?'
+
+var codePage *CodePage = CP437
+
+// SetCodePage sets the code page used to convert text to font characters. This should match the layout of the fonts
+// you're using.
+func SetCodePage(cp *CodePage) {
+	if cp == nil {
+		log.Error("Cannot set nil code page.")
+		return
+	}
+
+	codePage = cp
+}
+
+// GetCodePage returns the active code page.
+func GetCodePage() *CodePage {
+	return codePage
+}
+
+// Runes in this range of the unicode private use area are mapped directly to font characters, regardless of code page.
+// See FontRune().
+const fontRuneBase rune = 0xE000
+
+// FontRune returns a rune that will always be drawn as the provided font character, whatever the active code page is.
+// Use this to put specific font characters into strings.
+func FontRune(index uint8) rune {
+	return fontRuneBase + rune(index)
+}
+
+// MapRune converts a rune to the font character used to draw it, using the active code page. Runes with no mapping are
+// converted to the ReplacementChar.
+func MapRune(r rune) uint8 {
+	if index, ok := codePage.Index(r); ok {
+		return index
+	}
+
+	if r >= fontRuneBase && r <= fontRuneBase+255 {
+		return uint8(r - fontRuneBase)
+	}
+
+	return ReplacementChar
+}
@@ -25,8 +25,13 @@ var DefaultTextMode TextMode = TEXTMODE_FULL
 
 // Draws text to the canvas, starting at pos and respecting depth. If drawing in half-width mode, start_pos determines
 // which side of the cell the text begins in. Optionally takes a Textmode; if this is omitted, uses the defined default
-// mode.
+// mode. Text can contain markup tags for styling portions of it, see ParseMarkup() for details.
 func (c *Canvas) DrawText(pos vec.Coord, depth int, text string, colours col.Pair, start_pos TextCellPosition, text_mode ...TextMode) {
+	c.DrawStyledText(pos, depth, ParseMarkup(text, colours), start_pos, text_mode...)
+}
+
+// DrawStyledText draws text that has already been parsed from markup. Line breaks are not drawn. See DrawText().
+func (c *Canvas) DrawStyledText(pos vec.Coord, depth int, text StyledText, start_pos TextCellPosition, text_mode ...TextMode) {
 	var mode TextMode
 	if len(text_mode) == 0 {
 		mode = DefaultTextMode
@@ -39,46 +44,95 @@ func (c *Canvas) DrawText(pos vec.Coord, depth int, text string, colours col.Pai
 
 	switch mode {
 	case TEXTMODE_FULL:
-		c.DrawFullWidthText(pos, depth, text, colours)
+		c.drawFullWidthStyledText(pos, depth, text)
 	case TEXTMODE_HALF:
-		c.DrawHalfWidthText(pos, depth, text, colours, start_pos)
+		c.drawHalfWidthStyledText(pos, depth, text, start_pos)
 	default:
 		log.Error("bad textmode????")
 	}
 }
 
 // DrawText draws the provided string to the canvas using the half-width text drawing mode, beginning at pos and
-// respecting depth. start_pos specifies which side of the cell we begin drawing in.
+// respecting depth. start_pos specifies which side of the cell we begin drawing in. Markup is not parsed, the text is
+// drawn as-is.
 func (c *Canvas) DrawHalfWidthText(pos vec.Coord, depth int, text string, colours col.Pair, start_pos TextCellPosition) {
-	//build []rune version of txt string
-	var textRunes []rune = make([]rune, 0, len(text))
-	if start_pos == DRAW_TEXT_RIGHT { //pad start with a space if we're starting on the right
-		textRunes = append(textRunes, rune(TEXT_NONE))
+	c.drawHalfWidthStyledText(pos, depth, PlainStyledText(text, colours), start_pos)
+}
+
+// DrawFullWidthText draws the provided string to the canvas using the full-width glyph drawing mode, beginning at pos and
+// respecting depth. Markup is not parsed, the text is drawn as-is.
+func (c *Canvas) DrawFullWidthText(pos vec.Coord, depth int, text string, colours col.Pair) {
+	c.drawFullWidthStyledText(pos, depth, PlainStyledText(text, colours))
+}
+
+// TextLength returns the number of characters that will be drawn for the text, ignoring markup tags and line breaks.
+// This is measured in characters (runes), not bytes, so text with accents and such is measured correctly.
+func TextLength(text string) (length int) {
+	for _, char := range ParseMarkup(text, col.Pair{}) {
+		if char.Char != '\n' || char.Glyph {
+			length++
+		}
+	}
+
+	return
+}
+
+// PlainStyledText converts text to StyledText without parsing any markup, so the text is drawn exactly as written.
+func PlainStyledText(text string, colours col.Pair) (styled StyledText) {
+	styled = make(StyledText, 0, len(text))
+	for _, r := range text {
+		styled = append(styled, StyledChar{Char: r, Colours: colours})
 	}
-	textRunes = append(textRunes, []rune(text)...)
-	if len(textRunes)%2 != 0 { //pad end if we're ending on the left
-		textRunes = append(textRunes, rune(TEXT_NONE))
+
+	return
+}
+
+// Half-width cells hold 2 characters but only 1 pair of colours. If the characters are styled differently, the left
+// one wins unless it's a space. Inserted glyphs are drawn using the text character with the same number. Other
+// characters are converted to font characters using the active code page, see MapRune().
+func (c *Canvas) drawHalfWidthStyledText(pos vec.Coord, depth int, text StyledText, start_pos TextCellPosition) {
+	//build padded version of the text, skipping line breaks
+	chars := make(StyledText, 0, len(text)+2)
+	if start_pos == DRAW_TEXT_RIGHT && len(text) > 0 { //pad start with a space if we're starting on the right
+		chars = append(chars, StyledChar{Char: rune(TEXT_NONE), Colours: text[0].Colours})
+	}
+	for _, char := range text {
+		if char.Char != '\n' || char.Glyph {
+			chars = append(chars, char)
+		}
+	}
+	if len(chars)%2 != 0 { //pad end if we're ending on the left
+		chars = append(chars, StyledChar{Char: rune(TEXT_NONE), Colours: chars[len(chars)-1].Colours})
 	}
 
-	//iterate by pairs of runes, drawing 1 cell per loop
-	for i := 0; i < len(textRunes); i += 2 {
+	//iterate by pairs of chars, drawing 1 cell per loop
+	for i := 0; i < len(chars); i += 2 {
 		cursor := vec.Coord{pos.X + i/2, pos.Y}
 		if !c.InBounds(cursor) { //make sure we're drawing in the canvas.
 			continue
 		}
 
-		c.setCell(cursor, depth, NewTextVisuals(uint8(textRunes[i]), uint8(textRunes[i+1]), colours))
+		colours := chars[i].Colours
+		if chars[i].Char == rune(TEXT_NONE) && !chars[i].Glyph {
+			colours = chars[i+1].Colours
+		}
+
+		c.setCell(cursor, depth, NewTextVisuals(chars[i].fontIndex(), chars[i+1].fontIndex(), colours))
 	}
 }
 
-// DrawFullWidthText draws the provided string to the canvas using the full-width glyph drawing mode, beginning at pos and
-// respecting depth.
-func (c *Canvas) DrawFullWidthText(pos vec.Coord, depth int, text string, colours col.Pair) {
-	for i, textRune := range []rune(text) {
+func (c *Canvas) drawFullWidthStyledText(pos vec.Coord, depth int, text StyledText) {
+	i := 0
+	for _, char := range text {
+		if char.Char == '\n' && !char.Glyph {
+			continue
+		}
+
 		cursor := pos.StepN(vec.DIR_RIGHT, i)
+		i++
 		if !c.InBounds(cursor) {
 			continue
 		}
-		c.setCell(cursor, depth, NewGlyphVisuals(Glyph(textRune), colours))
+		c.setCell(cursor, depth, NewGlyphVisuals(Glyph(char.fontIndex()), char.Colours))
 	}
 }
@@ -0,0 +1,291 @@
+package gfx
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bennicholls/tyumi/gfx/col"
+)
+
+// Text drawn with DrawText (and by extension ui.Textbox) can contain inline markup tags to style portions of the
+// text. Tags are enclosed in square brackets:
+//   - [fg=COLOUR] : sets the foreground colour
+//   - [bg=COLOUR] : sets the background colour
+//   - [em]        : emphasizes text, drawing it in the EmphasisColour
+//   - [g=GLYPH]   : inserts a glyph
+//   - [/]         : ends the most recent fg, bg, or em tag
+//
+// Colours can be any name found in col.ColourNames (case and spaces are ignored, so [fg=lightgrey] works) or a hex
+// code like #FF8800 or #80FF8800 (with alpha). Glyphs can be any name from GlyphNames (again ignoring case and spaces)
+// or a glyph number. Anything in brackets that isn't a valid tag is drawn as-is, so normal text like "[ESC] to quit"
+// is fine. To write a literal [ before something that looks like a tag, use [[.
+//
+// The old /n line break is also understood when wrapping text. To write a literal /n, use //n.
+
+// EmphasisColour is the foreground colour used for text in [em] tags.
+var EmphasisColour col.Colour = col.YELLOW
+
+// StyledChar is a single character of text along with the colours to draw it in. If Glyph is true, the character is a
+// glyph inserted by a [g=GLYPH] tag and is drawn using the glyph font (in full-width mode anyways).
+type StyledChar struct {
+	Char    rune
+	Colours col.Pair
+	Glyph   bool
+}
+
+// returns the font character used to draw the char.
+func (sc StyledChar) fontIndex() uint8 {
+	if sc.Glyph {
+		return uint8(sc.Char)
+	}
+
+	return MapRune(sc.Char)
+}
+
+// StyledText is text that has been parsed from markup and is ready for wrapping and drawing.
+type StyledText []StyledChar
+
+// String returns the text without any styling.
+func (st StyledText) String() string {
+	var b strings.Builder
+	for _, c := range st {
+		b.WriteRune(c.Char)
+	}
+
+	return b.String()
+}
+
+// ParseMarkup converts text with markup tags to StyledText. Untagged text is styled with the provided colours. Line
+// breaks (either \n or /n) are kept as '\n' characters so they can be respected when wrapping.
+func ParseMarkup(text string, colours col.Pair) (styled StyledText) {
+	styled = make(StyledText, 0, len(text))
+	styles := []col.Pair{colours}
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		current := styles[len(styles)-1]
+		switch r := runes[i]; {
+		case r == '/' && i+2 < len(runes) && runes[i+1] == '/' && runes[i+2] == 'n':
+			styled = append(styled, StyledChar{Char: '/', Colours: current}, StyledChar{Char: 'n', Colours: current})
+			i += 2
+		case r == '/' && i+1 < len(runes) && runes[i+1] == 'n':
+			styled = append(styled, StyledChar{Char: '\n', Colours: current})
+			i++
+		case r == '[' && i+1 < len(runes) && runes[i+1] == '[':
+			styled = append(styled, StyledChar{Char: '[', Colours: current})
+			i++
+		case r == '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' && runes[end] != '[' {
+				end++
+			}
+
+			if end == len(runes) || runes[end] != ']' {
+				styled = append(styled, StyledChar{Char: r, Colours: current})
+				continue
+			}
+
+			tag := string(runes[i+1 : end])
+			if tag == "/" {
+				if len(styles) > 1 {
+					styles = styles[:len(styles)-1]
+				}
+			} else if glyph, ok := parseGlyphTag(tag); ok {
+				styled = append(styled, StyledChar{Char: rune(glyph), Colours: current, Glyph: true})
+			} else if style, ok := parseStyleTag(tag, current); ok {
+				styles = append(styles, style)
+			} else { // not a tag, just some text in brackets
+				styled = append(styled, StyledChar{Char: r, Colours: current})
+				continue
+			}
+
+			i = end
+		default:
+			styled = append(styled, StyledChar{Char: r, Colours: current})
+		}
+	}
+
+	return
+}
+
+// StripMarkup returns the text with all markup tags removed. Line breaks are left as '\n' characters.
+func StripMarkup(text string) string {
+	return ParseMarkup(text, col.Pair{}).String()
+}
+
+var markupEscaper = strings.NewReplacer("[", "[[", "/n", "//n")
+
+// EscapeMarkup escapes any brackets and /n line breaks in the text so it will be drawn exactly as written. Useful for
+// drawing text you don't control, like a name the player typed in or a file path.
+func EscapeMarkup(text string) string {
+	return markupEscaper.Replace(text)
+}
+
+func parseStyleTag(tag string, current col.Pair) (style col.Pair, ok bool) {
+	style = current
+	if tag == "em" {
+		style.Fore = EmphasisColour
+		return style, true
+	}
+
+	key, value, found := strings.Cut(tag, "=")
+	if !found {
+		return
+	}
+
+	colour, ok := parseColour(value)
+	if !ok {
+		return
+	}
+
+	switch key {
+	case "fg":
+		style.Fore = colour
+	case "bg":
+		style.Back = colour
+	default:
+		return style, false
+	}
+
+	return
+}
+
+func parseGlyphTag(tag string) (glyph Glyph, ok bool) {
+	value, found := strings.CutPrefix(tag, "g=")
+	if !found {
+		return
+	}
+
+	if n, err := strconv.ParseUint(value, 10, 8); err == nil {
+		return Glyph(n), true
+	}
+
+	value = normalizeMarkupName(value)
+	for glyph, name := range GlyphNames {
+		if normalizeMarkupName(name) == value {
+			return glyph, true
+		}
+	}
+
+	return
+}
+
+func parseColour(value string) (colour col.Colour, ok bool) {
+	if hex, found := strings.CutPrefix(value, "#"); found {
+		if len(hex) != 6 && len(hex) != 8 {
+			return
+		}
+
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return
+		}
+
+		colour = col.Colour(n)
+		if len(hex) == 6 {
+			colour |= 0xFF000000
+		}
+
+		return colour, true
+	}
+
+	value = normalizeMarkupName(value)
+	for colour, name := range col.ColourNames {
+		if normalizeMarkupName(name) == value {
+			return colour, true
+		}
+	}
+
+	return
+}
+
+func normalizeMarkupName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", ""))
+}
+
+// Wrap breaks the text into lines no longer than width characters, breaking at spaces and line breaks. Words longer
+// than the width are cut off. Since styling is stored per character, styled spans carry across line breaks. If
+// maxlines is provided, stops after that many lines.
+func (st StyledText) Wrap(width int, maxlines ...int) (lines []StyledText) {
+	capped := false
+	if len(maxlines) == 1 {
+		lines = make([]StyledText, 0, maxlines[0])
+		capped = true
+	} else {
+		lines = make([]StyledText, 0)
+	}
+
+	if width <= 0 {
+		return
+	}
+
+	currentLine := make(StyledText, 0, width)
+	for broken := range splitStyled(st, '\n') {
+		for word := range splitStyled(broken, ' ') {
+			word = trimStyled(word) //get rid of nasty tabs and other weird whitespace.
+
+			//super long word make-it-not-break hack.
+			if len(word) > width {
+				word = word[:width]
+			}
+
+			//add a line if current word won't fit
+			if len(currentLine)+len(word) > width {
+				lines = append(lines, trimStyled(currentLine))
+				currentLine = make(StyledText, 0, width)
+
+				//break if number of lines == height
+				if capped && len(lines) == cap(lines) {
+					return
+				}
+			}
+
+			currentLine = append(currentLine, word...)
+			if len(currentLine) != width && len(word) > 0 {
+				// spaces take the style of the character before them, so background colours extend between words
+				currentLine = append(currentLine, StyledChar{Char: ' ', Colours: word[len(word)-1].Colours})
+			}
+		}
+
+		lines = append(lines, trimStyled(currentLine))
+		currentLine = make(StyledText, 0, width)
+
+		if capped && len(lines) == cap(lines) {
+			return
+		}
+	}
+
+	return
+}
+
+// splits the text on the provided character, yielding each piece. like strings.SplitSeq.
+func splitStyled(st StyledText, sep rune) func(yield func(StyledText) bool) {
+	return func(yield func(StyledText) bool) {
+		start := 0
+		for i, c := range st {
+			if c.Char == sep && !c.Glyph {
+				if !yield(st[start:i]) {
+					return
+				}
+				start = i + 1
+			}
+		}
+		yield(st[start:])
+	}
+}
+
+// trims whitespace (but not glyphs, which might be spaces i guess) from both ends of the text.
+func trimStyled(st StyledText) StyledText {
+	isSpace := func(c StyledChar) bool {
+		return !c.Glyph && strings.ContainsRune(" \t\r\n", c.Char)
+	}
+
+	for len(st) > 0 && isSpace(st[0]) {
+		st = st[1:]
+	}
+	for len(st) > 0 && isSpace(st[len(st)-1]) {
+		st = st[:len(st)-1]
+	}
+
+	return st
+}
@@ -0,0 +1,123 @@
+package gfx
+
+import (
+	"testing"
+
+	"github.com/bennicholls/tyumi/gfx/col"
+)
+
+var markupTestColours = col.Pair{col.WHITE, col.BLACK}
+
+func TestParseMarkup(t *testing.T) {
+	tests := []struct {
+		markup string
+		text   string
+	}{
+		{"plain text", "plain text"},
+		{"[fg=red]red[/] text", "red text"},
+		{"[em]loud[/]", "loud"},
+		{"[ESC] to quit", "[ESC] to quit"},
+		{"unclosed [fg=red", "unclosed [fg=red"},
+		{"[[fg=red]", "[fg=red]"},
+		{"line/nbreak", "line\nbreak"},
+		{"line\nbreak", "line\nbreak"},
+		{"/root//nope", "/root/nope"},
+		{"[/]extra end", "extra end"},
+	}
+
+	for _, test := range tests {
+		if text := ParseMarkup(test.markup, markupTestColours).String(); text != test.text {
+			t.Errorf("ParseMarkup(%q) = %q, wanted %q", test.markup, text, test.text)
+		}
+	}
+}
+
+func TestParseMarkupStyles(t *testing.T) {
+	styled := ParseMarkup("a[fg=red]b[bg=#0000FF]c[/]d[/]e[em]f", markupTestColours)
+	want := []col.Pair{
+		markupTestColours,
+		{col.RED, col.BLACK},
+		{col.RED, col.BLUE},
+		{col.RED, col.BLACK},
+		markupTestColours,
+		{EmphasisColour, col.BLACK},
+	}
+
+	if len(styled) != len(want) {
+		t.Fatalf("Parsed %d characters, wanted %d", len(styled), len(want))
+	}
+
+	for i, char := range styled {
+		if char.Colours != want[i] {
+			t.Errorf("Character %c styled %v, wanted %v", char.Char, char.Colours, want[i])
+		}
+	}
+
+	glyph := ParseMarkup("[g=face1][g=3]", markupTestColours)
+	if len(glyph) != 2 || !glyph[0].Glyph || Glyph(glyph[0].Char) != GLYPH_FACE1 || !glyph[1].Glyph || glyph[1].Char != 3 {
+		t.Errorf("Glyph tags parsed incorrectly: %v", glyph)
+	}
+}
+
+func TestEscapeMarkup(t *testing.T) {
+	for _, text := range []string{
+		"[fg=red]not red[/]",
+		"/root/module/nope",
+		"//n and ///n",
+		"[[already escaped",
+		"[g=heart]",
+	} {
+		if escaped := ParseMarkup(EscapeMarkup(text), markupTestColours).String(); escaped != text {
+			t.Errorf("Escaped %q parsed to %q", text, escaped)
+		}
+	}
+}
+
+func TestStyledTextWrap(t *testing.T) {
+	lines := ParseMarkup("the quick [fg=red]brown fox[/] jumps/nover the lazy dog", markupTestColours).Wrap(10)
+	want := []string{"the quick", "brown fox", "jumps", "over the", "lazy dog"}
+
+	if len(lines) != len(want) {
+		t.Fatalf("Wrapped to %d lines, wanted %d: %v", len(lines), len(want), lines)
+	}
+
+	for i, line := range lines {
+		if line.String() != want[i] {
+			t.Errorf("Line %d is %q, wanted %q", i, line.String(), want[i])
+		}
+	}
+
+	// styling follows the characters onto the next line
+	if lines[1][0].Colours.Fore != col.RED || lines[1][len(lines[1])-1].Colours.Fore != col.RED {
+		t.Error("Styling lost when wrapping.")
+	}
+
+	if capped := ParseMarkup("one two three four", markupTestColours).Wrap(5, 2); len(capped) != 2 {
+		t.Errorf("Wrapped to %d lines, wanted 2 (capped)", len(capped))
+	}
+
+	if long := ParseMarkup("abcdefghij", markupTestColours).Wrap(4); len(long) != 1 || long[0].String() != "abcd" {
+		t.Errorf("Long word wrapped incorrectly: %v", long)
+	}
+}
+
+func TestTextLength(t *testing.T) {
+	tests := []struct {
+		text   string
+		length int
+	}{
+		{"hello", 5},
+		{"[fg=red]hello[/]", 5},
+		{"héllo", 5},
+		{"two/nlines", 8},
+		{"[g=heart]", 1},
+		{"[[", 1},
+		{"[not a tag]", 11},
+	}
+
+	for _, test := range tests {
+		if length := TextLength(test.text); length != test.length {
+			t.Errorf("TextLength(%q) = %d, wanted %d", test.text, length, test.length)
+		}
+	}
+}
@@ -186,9 +186,9 @@ func (e *Element) drawBorder() {
 		offset := 0
 		switch style.TitleAlignment {
 		case ALIGN_CENTER:
-			offset = (e.size.W - len(decoratedTitle)/2) / 2
+			offset = (e.size.W - gfx.TextLength(decoratedTitle)/2) / 2
 		case ALIGN_RIGHT:
-			offset = e.size.W - len([]rune(decoratedTitle))/2 - 1
+			offset = e.size.W - gfx.TextLength(decoratedTitle)/2 - 1
 		}
 		e.DrawText(vec.Coord{offset, -1}, BorderDepth+1, decoratedTitle, style.Colours, gfx.DRAW_TEXT_LEFT)
 	}
@@ -199,9 +199,9 @@ func (e *Element) drawBorder() {
 		offset := 0
 		switch style.HintAlignment {
 		case ALIGN_CENTER:
-			offset = (e.size.W - len(decoratedHint)/2) / 2
+			offset = (e.size.W - gfx.TextLength(decoratedHint)/2) / 2
 		case ALIGN_RIGHT:
-			offset = e.size.W - len([]rune(decoratedHint))/2
+			offset = e.size.W - gfx.TextLength(decoratedHint)/2
 		}
 		e.DrawText(vec.Coord{offset, rect.H - 2}, BorderDepth+1, decoratedHint, style.Colours, 0)
 	}
@@ -43,19 +43,19 @@ func (bs BorderStyle) GetGlyph(neighbour_flags int) gfx.Glyph {
 
 func (bs BorderStyle) decorateText(text string, align Alignment) (decorated_text string) {
 	if bs.TextDecorationL != 0 {
-		decorated_text += string(bs.TextDecorationL)
+		decorated_text += string(gfx.FontRune(bs.TextDecorationL))
 	}
 	decorated_text += text
 	if bs.TextDecorationR != 0 {
-		decorated_text += string(bs.TextDecorationR)
+		decorated_text += string(gfx.FontRune(bs.TextDecorationR))
 	}
 
-	if len([]rune(decorated_text))%2 == 1 {
+	if gfx.TextLength(decorated_text)%2 == 1 {
 		switch align {
 		case ALIGN_LEFT, ALIGN_CENTER:
-			decorated_text += string(bs.TextDecorationPad)
+			decorated_text += string(gfx.FontRune(bs.TextDecorationPad))
 		case ALIGN_RIGHT:
-			decorated_text = string(bs.TextDecorationPad) + decorated_text
+			decorated_text = string(gfx.FontRune(bs.TextDecorationPad)) + decorated_text
 		}
 	}
 
@@ -66,3 +66,13 @@ func (b *Button) HandleAction(action input.ActionID) (action_handled bool) {
 
 	return true
 }
+
+// Buttons are pressed by clicking them with the left mouse button.
+func (b *Button) HandleClick(click_event *input.MouseButtonEvent) (event_handled bool) {
+	if click_event.Button != input.MOUSE_LEFT {
+		return false
+	}
+
+	b.Press()
+	return true
+}
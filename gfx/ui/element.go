@@ -42,6 +42,11 @@ type element interface {
 	acceptsInput() bool
 	HandleKeypress(*input.KeyboardEvent) (event_handled bool)
 	HandleAction(action input.ActionID) (action_handled bool)
+	HandleClick(click_event *input.MouseButtonEvent) (event_handled bool)
+	IsHovered() bool
+	setHovered(hovered bool)
+	onMousePress(mouse_event *input.MouseButtonEvent)
+	onClick(click_event *input.MouseButtonEvent)
 
 	MoveTo(vec.Coord)
 	Move(dx, dy int)
@@ -92,8 +97,15 @@ type Element struct {
 	OnRender    func() // a callback, called when the element renders (unless the element has custom rendering logic)
 	Border      Border //the element's border data. use EnableBorder() to turn on
 
+	// mouse callbacks. positions in mouse events are local to the element's drawable area.
+	OnMouseEnter func()                                    // called when the mouse moves over the element or its children
+	OnMouseLeave func()                                    // called when the mouse leaves the element and its children
+	OnMousePress func(mouse_event *input.MouseButtonEvent) // called when a mouse button is pressed over the element
+	OnClick      func(click_event *input.MouseButtonEvent) // called when a mouse button is pressed and released over the element
+
 	visible     bool      //visibility, controlled via Show() and Hide()
 	focused     bool      //focus state. by default, only focused elements receive input
+	hovered     bool      //mouse is over the element (or one of its children)
 	forceRedraw bool      //indicates this object needs to clear and render everything from zero
 	position    vec.Coord //position relative to parent
 	size        vec.Dims  //size of the drawable area of the element
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"time"
+	"unicode/utf8"
 
 	"github.com/bennicholls/tyumi/gfx"
 	"github.com/bennicholls/tyumi/gfx/col"
@@ -39,6 +40,7 @@ func NewInputbox(size vec.Dims, pos vec.Coord, depth, input_length int) (ib *Inp
 func (ib *InputBox) Init(size vec.Dims, pos vec.Coord, depth, input_length int) {
 	ib.Textbox.Init(size, pos, depth, "", ALIGN_LEFT)
 	ib.TreeNode.Init(ib)
+	ib.SetMarkupEnabled(false) // typed text is displayed exactly as written
 
 	if input_length > 0 {
 		ib.inputLengthMax = input_length
@@ -55,7 +57,8 @@ func (ib *InputBox) ChangeText(text string) {
 	}
 
 	ib.Textbox.ChangeText(text)
-	ib.cursor.MoveTo(len(ib.text)/2, 0, len(ib.text)%2)
+	length := len(ib.styledText())
+	ib.cursor.MoveTo(length/2, 0, length%2)
 	fireCallbacks(ib.OnTextChanged)
 }
 
@@ -105,7 +108,7 @@ func (ib *InputBox) HandleAction(action input.ActionID) (action_handled bool) {
 // Appends the provided string to the contents of the inputbox.
 func (ib *InputBox) Insert(input string) {
 	new_text := ib.text + input
-	if len(new_text) > ib.inputLengthMax {
+	if utf8.RuneCountInString(new_text) > ib.inputLengthMax {
 		return
 	}
 
@@ -119,7 +122,8 @@ func (ib *InputBox) Delete() {
 		return
 	}
 
-	ib.ChangeText(ib.text[:len(ib.text)-1])
+	_, size := utf8.DecodeLastRuneInString(ib.text)
+	ib.ChangeText(ib.text[:len(ib.text)-size])
 	fireCallbacks(ib.OnTextDeleted)
 }
 
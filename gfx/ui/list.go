@@ -297,6 +297,29 @@ func (l *List) updateScrollPosition() {
 	l.Border.UpdateScrollbar(l.contentHeight, l.scrollOffset)
 }
 
+// Enables selection of list items. If the list has items, the first one is selected.
+func (l *List) EnableSelection() {
+	if l.selectionEnabled {
+		return
+	}
+
+	l.selectionEnabled = true
+	l.Select(0)
+}
+
+// Disables selection of list items. Also disables highlighting.
+func (l *List) DisableSelection() {
+	if !l.selectionEnabled {
+		return
+	}
+
+	l.highlight = false
+	l.selectionEnabled = false
+	l.selectionIndex = -1
+	fireCallbacks(l.OnChangeSelection)
+	l.Updated = true
+}
+
 // Enables list element highlighting for the currently selected element.
 func (l *List) EnableHighlight() {
 	l.setHighlight(true)
@@ -476,3 +499,19 @@ func (l *List) HandleAction(action input.ActionID) (action_handled bool) {
 
 	return true
 }
+
+// Clicking on a list item with the left mouse button selects it (if selection is enabled).
+func (l *List) HandleClick(click_event *input.MouseButtonEvent) (event_handled bool) {
+	if !l.selectionEnabled || click_event.Button != input.MOUSE_LEFT {
+		return false
+	}
+
+	for i, item := range l.items {
+		if item.IsVisible() && click_event.Position.IsInside(item) {
+			l.Select(i)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,259 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bennicholls/tyumi/gfx"
+	"github.com/bennicholls/tyumi/gfx/col"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// MessageCategory tags messages in a MessageLog so they can be coloured differently. Categories are defined by the
+// game; category 0 is the default and is drawn using the log's default colours unless a colour is set for it.
+type MessageCategory uint8
+
+// Message is a single message in a MessageLog.
+type Message struct {
+	Text     string          // can contain markup, see gfx.ParseMarkup()
+	Category MessageCategory // determines the colour of the message
+	Turn     int             // turn the message was most recently logged on
+	Count    int             // number of times the message was logged in a row
+}
+
+// String returns the message text, with the repeat count appended if the message was logged more than once.
+func (m Message) String() string {
+	if m.Count > 1 {
+		return fmt.Sprintf("%s (x%d)", m.Text, m.Count)
+	}
+
+	return m.Text
+}
+
+// a single item in the log's list. holds 1 message, or all messages from the same turn if turns are grouped.
+type messageLogEntry struct {
+	turn     int
+	messages []*Message
+	textbox  *Textbox
+}
+
+// MessageLog is a List for displaying the classic roguelike message log. Messages are tagged with the current turn
+// (set using SetTurn()) and coloured by category. If the same message is logged more than once in a row, the messages
+// are collapsed into one: "You hit the rat (x3)". If FadeAge is set, messages fade out as they get older. New messages
+// are scrolled into view automatically, and older messages can be reviewed by scrolling up.
+type MessageLog struct {
+	List
+
+	GroupTurns bool       // if true, all messages from the same turn are shown together in one entry
+	FadeAge    int        // number of turns it takes for a message to fade to the FadeColour. if 0, messages never fade.
+	FadeColour col.Colour // colour that old messages fade to. defaults to DARKGREY
+
+	turn            int
+	entries         []*messageLogEntry
+	categoryColours map[MessageCategory]col.Colour
+	scrollPending   bool // set when messages change, so we can scroll to the bottom once the list has been recalibrated
+}
+
+func NewMessageLog(size vec.Dims, pos vec.Coord, depth int) (ml *MessageLog) {
+	ml = new(MessageLog)
+	ml.Init(size, pos, depth)
+
+	return
+}
+
+func (ml *MessageLog) Init(size vec.Dims, pos vec.Coord, depth int) {
+	ml.List.Init(size, pos, depth)
+	ml.TreeNode.Init(ml)
+
+	ml.FadeColour = col.DARKGREY
+	ml.categoryColours = make(map[MessageCategory]col.Colour)
+}
+
+// SetCategoryColour sets the colour used to draw messages in the provided category.
+func (ml *MessageLog) SetCategoryColour(category MessageCategory, colour col.Colour) {
+	if current, ok := ml.categoryColours[category]; ok && current == colour {
+		return
+	}
+
+	ml.categoryColours[category] = colour
+	ml.refreshAll()
+}
+
+// SetDefaultColours sets the default colours for the log. Messages without a category colour are redrawn in the new
+// foreground colour.
+func (ml *MessageLog) SetDefaultColours(colours col.Pair) {
+	if colours == ml.DefaultColours() {
+		return
+	}
+
+	ml.List.SetDefaultColours(colours)
+	ml.refreshColours()
+}
+
+// SetDefaultVisuals sets the default visuals for the log. Like SetDefaultColours(), messages are redrawn to match.
+func (ml *MessageLog) SetDefaultVisuals(vis gfx.Visuals) {
+	if vis == ml.DefaultVisuals() {
+		return
+	}
+
+	ml.List.SetDefaultVisuals(vis)
+	ml.refreshColours()
+}
+
+// SetTurn sets the current turn. Messages added after this are tagged with the new turn, and if fading is enabled all
+// messages are faded according to their new age.
+func (ml *MessageLog) SetTurn(turn int) {
+	if ml.turn == turn {
+		return
+	}
+
+	ml.turn = turn
+	if ml.FadeAge > 0 {
+		ml.refreshAll()
+	}
+}
+
+// Turn returns the current turn, as set by SetTurn().
+func (ml *MessageLog) Turn() int {
+	return ml.turn
+}
+
+// AddMessage logs a message, tagged with the current turn. Optionally takes a category to colour the message with. If
+// the message is the same as the most recent one, the two are collapsed together and the count is increased instead.
+// If turns are grouped, messages are only collapsed with ones from the same turn.
+func (ml *MessageLog) AddMessage(text string, category ...MessageCategory) {
+	var cat MessageCategory
+	if len(category) > 0 {
+		cat = category[0]
+	}
+
+	if last, entry := ml.lastMessage(); last != nil && last.Text == text && last.Category == cat &&
+		(!ml.GroupTurns || entry.turn == ml.turn) {
+		last.Count += 1
+		last.Turn = ml.turn
+		ml.refreshEntry(entry)
+		return
+	}
+
+	msg := &Message{Text: text, Category: cat, Turn: ml.turn, Count: 1}
+	if ml.GroupTurns && len(ml.entries) > 0 {
+		if entry := ml.entries[len(ml.entries)-1]; entry.turn == ml.turn {
+			entry.messages = append(entry.messages, msg)
+			ml.refreshEntry(entry)
+			return
+		}
+	}
+
+	entry := &messageLogEntry{turn: ml.turn, messages: []*Message{msg}}
+	entry.textbox = NewTextbox(vec.Dims{ml.size.W, FIT_TEXT}, vec.ZERO_COORD, 0, ml.entryText(entry), ALIGN_LEFT)
+	entry.textbox.SetDefaultColours(ml.DefaultColours())
+	ml.entries = append(ml.entries, entry)
+	ml.Insert(entry.textbox)
+	ml.syncEntries()
+	ml.scrollPending = true
+}
+
+// Messages returns all messages in the log, from oldest to newest.
+func (ml *MessageLog) Messages() (messages []Message) {
+	messages = make([]Message, 0, len(ml.entries))
+	for _, entry := range ml.entries {
+		for _, msg := range entry.messages {
+			messages = append(messages, *msg)
+		}
+	}
+
+	return
+}
+
+// Clear removes all messages from the log.
+func (ml *MessageLog) Clear() {
+	ml.RemoveAll()
+	ml.entries = nil
+}
+
+// SetCapacity sets the maximum number of entries the log can hold. If turns are grouped, each entry can contain
+// multiple messages. See List.SetCapacity().
+func (ml *MessageLog) SetCapacity(cap int) {
+	ml.List.SetCapacity(cap)
+	ml.syncEntries()
+}
+
+// list removes the oldest items itself when it is at capacity, so drop any entries it has gotten rid of.
+func (ml *MessageLog) syncEntries() {
+	if trim := len(ml.entries) - ml.Count(); trim > 0 {
+		ml.entries = ml.entries[trim:]
+	}
+}
+
+func (ml *MessageLog) lastMessage() (*Message, *messageLogEntry) {
+	if len(ml.entries) == 0 {
+		return nil, nil
+	}
+
+	entry := ml.entries[len(ml.entries)-1]
+	return entry.messages[len(entry.messages)-1], entry
+}
+
+func (ml *MessageLog) refreshAll() {
+	for _, entry := range ml.entries {
+		ml.refreshEntry(entry)
+	}
+}
+
+// updates the colours of all entries to match the log's default colours.
+func (ml *MessageLog) refreshColours() {
+	for _, entry := range ml.entries {
+		entry.textbox.SetDefaultColours(ml.DefaultColours())
+	}
+
+	ml.refreshAll()
+}
+
+func (ml *MessageLog) refreshEntry(entry *messageLogEntry) {
+	height := entry.textbox.Size().H
+	entry.textbox.ChangeText(ml.entryText(entry))
+	if entry.textbox.Size().H != height {
+		ml.recalibrate = true
+		ml.scrollPending = true
+	}
+
+	ml.Updated = true
+}
+
+func (ml *MessageLog) entryText(entry *messageLogEntry) string {
+	texts := make([]string, 0, len(entry.messages))
+	for _, msg := range entry.messages {
+		if colour := ml.messageColour(*msg); colour.IsTransparent() {
+			texts = append(texts, msg.String())
+		} else {
+			texts = append(texts, fmt.Sprintf("[fg=#%08X]%s[/]", uint32(colour), msg.String()))
+		}
+	}
+
+	return strings.Join(texts, " ")
+}
+
+func (ml *MessageLog) messageColour(msg Message) col.Colour {
+	colour, ok := ml.categoryColours[msg.Category]
+	if !ok {
+		colour = ml.DefaultColours().Fore
+	}
+
+	if ml.FadeAge > 0 && !colour.IsTransparent() {
+		colour = colour.Lerp(ml.FadeColour, ml.turn-msg.Turn, ml.FadeAge)
+	}
+
+	return colour
+}
+
+func (ml *MessageLog) prepareRender() {
+	if ml.scrollPending {
+		if ml.recalibrate {
+			ml.calibrate()
+		}
+		ml.ScrollToBottom()
+		ml.scrollPending = false
+	}
+
+	ml.List.prepareRender()
+}
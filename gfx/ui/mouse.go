@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"slices"
+
+	"github.com/bennicholls/tyumi/input"
+	"github.com/bennicholls/tyumi/util"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// Mouse handling for UI elements. Windows hit-test mouse events against their element tree, dispatching them to the
+// topmost visible element under the cursor. An element is considered to be under the cursor if the cursor is over the
+// element itself or any of its children, so hovering over a button in a list hovers both the button and the list.
+//
+// Elements can respond to the mouse in 2 ways: by setting the OnMouseEnter/OnMouseLeave/OnMousePress/OnClick
+// callbacks, or by overriding HandleClick(). Callbacks are called for every element under the cursor, from the top
+// down. HandleClick() is called on the clicked element first, then on each of its parents in turn until one of them
+// handles the click.
+
+// HandleClick handles mouse clicks. Override this to implement click handling. The position of the click_event is
+// local to the element's drawable area.
+func (e *Element) HandleClick(click_event *input.MouseButtonEvent) (event_handled bool) { return }
+
+// IsHovered returns true if the mouse cursor is over the element or any of its children.
+func (e *Element) IsHovered() bool {
+	return e.hovered
+}
+
+func (e *Element) setHovered(hovered bool) {
+	if e.hovered == hovered {
+		return
+	}
+
+	e.hovered = hovered
+	if e.hovered {
+		fireCallbacks(e.OnMouseEnter)
+	} else {
+		fireCallbacks(e.OnMouseLeave)
+	}
+}
+
+func (e *Element) onMousePress(mouse_event *input.MouseButtonEvent) {
+	if e.OnMousePress != nil {
+		e.OnMousePress(mouse_event)
+	}
+}
+
+func (e *Element) onClick(click_event *input.MouseButtonEvent) {
+	if e.OnClick != nil {
+		e.OnClick(click_event)
+	}
+}
+
+// HandleMouseMove updates which elements in the window are hovered by the mouse. pos is relative to the window's
+// parent (for scenes and dialogs, this is the console).
+func (wnd *Window) HandleMouseMove(pos vec.Coord) {
+	hovered := wnd.findElementsAt(pos.Subtract(wnd.position))
+
+	for _, e := range wnd.hoveredElements {
+		if !slices.Contains(hovered, e) {
+			e.setHovered(false)
+		}
+	}
+
+	for _, e := range hovered {
+		e.setHovered(true)
+	}
+
+	wnd.hoveredElements = hovered
+}
+
+// HandleMouseButton dispatches a mouse button event to the elements under the cursor. A press followed by a release
+// over the same element is a click. The position of the mouse_event is relative to the window's parent (for scenes
+// and dialogs, this is the console). Returns true if a click was handled by an element.
+func (wnd *Window) HandleMouseButton(mouse_event *input.MouseButtonEvent) (event_handled bool) {
+	pos := mouse_event.Position.Subtract(wnd.position)
+	under_cursor := wnd.findElementsAt(pos)
+
+	switch mouse_event.PressType {
+	case input.KEY_PRESSED:
+		if len(under_cursor) == 0 {
+			delete(wnd.pressedElements, mouse_event.Button)
+			return
+		}
+
+		wnd.pressedElements[mouse_event.Button] = under_cursor[0]
+		for _, e := range under_cursor {
+			local_event := *mouse_event
+			local_event.Position = wnd.toLocal(e, pos)
+			e.onMousePress(&local_event)
+		}
+	case input.KEY_RELEASED:
+		pressed, ok := wnd.pressedElements[mouse_event.Button]
+		if !ok {
+			return
+		}
+
+		delete(wnd.pressedElements, mouse_event.Button)
+		if !slices.Contains(under_cursor, pressed) {
+			return // released somewhere else, so no click
+		}
+
+		for e := pressed; e != nil && e != element(wnd); e = e.GetParent() {
+			click_event := *mouse_event
+			click_event.Position = wnd.toLocal(e, pos)
+			e.onClick(&click_event)
+			if !event_handled {
+				event_handled = e.HandleClick(&click_event)
+			}
+		}
+	}
+
+	return
+}
+
+// finds the visible elements under pos (relative to the window's drawable area). The topmost element is first,
+// followed by each of its parents in turn. The window itself is not included.
+func (wnd *Window) findElementsAt(pos vec.Coord) (elements []element) {
+	if !pos.IsInside(wnd.DrawableArea()) {
+		return
+	}
+
+	return findChildrenAt(wnd, pos, elements)
+}
+
+// recursively finds the topmost child of e at pos (relative to e's drawable area). since e is only searched if pos is
+// inside it, children are effectively clipped by their parents just like when drawing.
+func findChildrenAt(e element, pos vec.Coord, elements []element) []element {
+	var top element
+	for _, child := range e.GetChildren() {
+		if !child.IsVisible() || !pos.IsInside(child) {
+			continue
+		}
+
+		// children with equal depths are drawn in order, so later children are on top.
+		if top == nil || child.getDepth() >= top.getDepth() {
+			top = child
+		}
+	}
+
+	if top == nil {
+		return elements
+	}
+
+	elements = findChildrenAt(top, pos.Subtract(top.getPosition()), elements)
+
+	return append(elements, top)
+}
+
+// converts pos from window coordinates to coordinates local to e's drawable area.
+func (wnd *Window) toLocal(e element, pos vec.Coord) vec.Coord {
+	for node := e; node != nil && node != element(wnd); node = node.GetParent() {
+		pos = pos.Subtract(node.getPosition())
+	}
+
+	return pos
+}
+
+// removes references to an element (and its children) from the window's mouse tracking. used when an element is
+// removed from the window.
+func (wnd *Window) forgetMouseElement(e element) {
+	if e.IsHovered() {
+		e.setHovered(false)
+		wnd.hoveredElements = util.DeleteElement(wnd.hoveredElements, e)
+	}
+
+	for button, pressed := range wnd.pressedElements {
+		if pressed == e {
+			delete(wnd.pressedElements, button)
+		}
+	}
+}
@@ -165,6 +165,22 @@ func (pc *PageContainer) HandleAction(action input.ActionID) (action_handled boo
 	return true
 }
 
+// Clicking on a page's tab with the left mouse button switches to that page.
+func (pc *PageContainer) HandleClick(click_event *input.MouseButtonEvent) (event_handled bool) {
+	if click_event.Button != input.MOUSE_LEFT {
+		return false
+	}
+
+	for i, page := range pc.pages {
+		if click_event.Position.IsInside(page.getTab()) {
+			pc.selectPage(i)
+			return true
+		}
+	}
+
+	return false
+}
+
 // Page is the content for a tab in a PageContainer. Size is defined and controlled by the PageContainer.
 // Pages are initialized as deactivated (hidden), and will be activated when selected by the page container.
 type Page struct {
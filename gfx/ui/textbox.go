@@ -3,7 +3,6 @@ package ui
 import (
 	"github.com/bennicholls/tyumi/gfx"
 	"github.com/bennicholls/tyumi/gfx/col"
-	"github.com/bennicholls/tyumi/util"
 	"github.com/bennicholls/tyumi/vec"
 )
 
@@ -25,8 +24,9 @@ type Textbox struct {
 	fit_height bool
 	alignment  Alignment
 	textMode   gfx.TextMode
-	text       string   //text to be displayed
-	lines      []string //text after it has been word wrapped
+	noMarkup   bool             //if true, text is displayed as-is without parsing markup
+	text       string           //text to be displayed. can contain markup, see gfx.ParseMarkup()
+	lines      []gfx.StyledText //text after it has been parsed and word wrapped
 }
 
 // Creates a textbox. You can set the width or height to FIT_TEXT to have the textbox compute the dimensions for you. If
@@ -53,7 +53,7 @@ func NewTitleTextbox(size vec.Dims, pos vec.Coord, depth int, text string) (tb *
 func (tb *Textbox) Init(size vec.Dims, pos vec.Coord, depth int, text string, align Alignment) {
 	tb.text = text
 	tb.alignment = align
-	tb.lines = make([]string, 0)
+	tb.lines = make([]gfx.StyledText, 0)
 
 	if size.W == FIT_TEXT {
 		tb.fit_width = true
@@ -89,6 +89,18 @@ func (tb *Textbox) SetTextMode(text_mode gfx.TextMode) {
 	tb.Updated = true
 }
 
+// SetMarkupEnabled sets whether markup in the text is parsed (see gfx.ParseMarkup()). Markup is enabled by default.
+// Disable it to display text exactly as written, like text typed in by the player.
+func (tb *Textbox) SetMarkupEnabled(enabled bool) {
+	if tb.noMarkup == !enabled {
+		return
+	}
+
+	tb.noMarkup = !enabled
+	tb.wrapText()
+	tb.Updated = true
+}
+
 // ChangeText changes the text content of the textbox. The new content is re-wrapped and if the textbox is set to
 // FIT_WIDTH or FIT_HEIGHT it will be resized to fit.
 func (tb *Textbox) ChangeText(txt string) {
@@ -111,30 +123,40 @@ func (tb *Textbox) AppendText(txt string) {
 	tb.ChangeText(tb.text + txt)
 }
 
+// returns the text styled and ready for wrapping, parsing markup if enabled.
+func (tb Textbox) styledText() gfx.StyledText {
+	if tb.noMarkup {
+		return gfx.PlainStyledText(tb.text, col.Pair{gfx.COL_DEFAULT, gfx.COL_DEFAULT})
+	}
+
+	return gfx.ParseMarkup(tb.text, col.Pair{gfx.COL_DEFAULT, gfx.COL_DEFAULT})
+}
+
 func (tb *Textbox) wrapText() {
 	size := tb.size
+	text := tb.styledText()
 	switch tb.getTextMode() {
 	case gfx.TEXTMODE_FULL:
 		if tb.fit_width {
-			size = vec.Dims{len(tb.text), 1}
-			tb.lines = make([]string, 1)
-			tb.lines[0] = tb.text
+			size = vec.Dims{len(text), 1}
+			tb.lines = make([]gfx.StyledText, 1)
+			tb.lines[0] = text
 		} else if tb.fit_height {
-			tb.lines = util.WrapText(tb.text, tb.size.W)
+			tb.lines = text.Wrap(tb.size.W)
 			size.H = len(tb.lines)
 		} else {
-			tb.lines = util.WrapText(tb.text, tb.size.W, tb.size.H)
+			tb.lines = text.Wrap(tb.size.W, tb.size.H)
 		}
 	case gfx.TEXTMODE_HALF:
 		if tb.fit_width {
-			size = vec.Dims{(len(tb.text) + 1) / 2, 1}
-			tb.lines = make([]string, 1)
-			tb.lines[0] = tb.text
+			size = vec.Dims{(len(text) + 1) / 2, 1}
+			tb.lines = make([]gfx.StyledText, 1)
+			tb.lines[0] = text
 		} else if tb.fit_height {
-			tb.lines = util.WrapText(tb.text, size.W*2)
+			tb.lines = text.Wrap(size.W * 2)
 			size.H = len(tb.lines)
 		} else {
-			tb.lines = util.WrapText(tb.text, tb.size.W*2, tb.size.H)
+			tb.lines = text.Wrap(tb.size.W*2, tb.size.H)
 		}
 	}
 
@@ -177,6 +199,6 @@ func (tb *Textbox) Render() {
 			}
 		}
 
-		tb.DrawText(pos, 0, line, col.Pair{gfx.COL_DEFAULT, gfx.COL_DEFAULT}, gfx.TextCellPosition(x_offset%2), tb.textMode)
+		tb.DrawStyledText(pos, 0, line, gfx.TextCellPosition(x_offset%2), tb.textMode)
 	}
 }
@@ -32,6 +32,9 @@ type Window struct {
 	SendEventsToUnfocused bool //if true, unhandled input events will be sent to all elements, not just the focused one.
 	focusedElement        element
 	tabbingOrder          []element
+
+	hoveredElements []element                     // elements under the mouse cursor, topmost first
+	pressedElements map[input.MouseButton]element // elements under the cursor when each mouse button was pressed
 }
 
 func NewWindow(size vec.Dims, pos vec.Coord, depth int) (wnd *Window) {
@@ -39,6 +42,7 @@ func NewWindow(size vec.Dims, pos vec.Coord, depth int) (wnd *Window) {
 	wnd.Init(size, pos, depth)
 	wnd.TreeNode.Init(wnd)
 	wnd.labels = make(map[string]element)
+	wnd.pressedElements = make(map[input.MouseButton]element)
 	return
 }
 
@@ -260,6 +264,8 @@ func (wnd *Window) onSubNodeRemoved(subNode element) {
 		if wnd.focusedElement == e {
 			wnd.focusedElement = nil
 		}
+
+		wnd.forgetMouseElement(e)
 	})
 }
 
@@ -8,10 +8,12 @@ require (
 	github.com/pkg/profile v1.7.0
 	github.com/veandco/go-sdl2 v0.4.40
 	golang.org/x/exp v0.0.0-20250506013437-ce4c2cf36ca6
+	golang.org/x/term v0.32.0
 )
 
 require (
 	github.com/ebitengine/purego v0.8.3 // indirect
 	github.com/felixge/fgprof v0.9.3 // indirect
 	github.com/google/pprof v0.0.0-20211214055906-6f57359322fd // indirect
+	golang.org/x/sys v0.33.0 // indirect
 )
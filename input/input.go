@@ -8,6 +8,7 @@ var (
 	EV_KEYBOARD    = event.Register("Key Event")
 	EV_MOUSEMOVE   = event.Register("Mouse Move Event")
 	EV_MOUSEBUTTON = event.Register("Mouse Button Event")
+	EV_MOUSEWHEEL  = event.Register("Mouse Wheel Event")
 )
 
 // Set this to true to have Tyumi emit key-repeat events when keys are held down
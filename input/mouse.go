@@ -1,6 +1,8 @@
 package input
 
 import (
+	"fmt"
+
 	"github.com/bennicholls/tyumi/event"
 	"github.com/bennicholls/tyumi/vec"
 )
@@ -23,3 +25,91 @@ func FireMouseMoveEvent(pos, delta vec.Coord) {
 
 	event.Fire(EV_MOUSEMOVE, &MouseMoveEvent{Position: pos, Delta: delta})
 }
+
+type MouseButton uint8
+
+const (
+	MOUSE_LEFT MouseButton = iota
+	MOUSE_MIDDLE
+	MOUSE_RIGHT
+	MOUSE_X1
+	MOUSE_X2
+)
+
+func (mb MouseButton) String() string {
+	switch mb {
+	case MOUSE_LEFT:
+		return "Left"
+	case MOUSE_MIDDLE:
+		return "Middle"
+	case MOUSE_RIGHT:
+		return "Right"
+	case MOUSE_X1:
+		return "X1"
+	case MOUSE_X2:
+		return "X2"
+	default:
+		return "???"
+	}
+}
+
+type MouseButtonEvent struct {
+	event.EventPrototype
+
+	Button      MouseButton
+	PressType   KeyPressType // KEY_PRESSED for button downs, KEY_RELEASED for button ups.
+	Position    vec.Coord    // position of the mouse in the console
+	DoubleClick bool         // true if this press is the 2nd press of a double click
+}
+
+func (mbe MouseButtonEvent) String() (s string) {
+	s = fmt.Sprintf("Mouse Button Event: %s button ", mbe.Button)
+	if mbe.PressType == KEY_PRESSED {
+		s += "pressed"
+		if mbe.DoubleClick {
+			s += " (double click)"
+		}
+	} else {
+		s += "released"
+	}
+
+	return s + " at " + mbe.Position.String()
+}
+
+// Emits mouse button press event. If double_click is true, the press is reported as the 2nd press of a double click.
+func FireMouseButtonPressEvent(button MouseButton, pos vec.Coord, double_click bool) {
+	if !EnableMouse {
+		return
+	}
+
+	event.Fire(EV_MOUSEBUTTON, &MouseButtonEvent{Button: button, PressType: KEY_PRESSED, Position: pos, DoubleClick: double_click})
+}
+
+// Emits mouse button release event.
+func FireMouseButtonReleaseEvent(button MouseButton, pos vec.Coord) {
+	if !EnableMouse {
+		return
+	}
+
+	event.Fire(EV_MOUSEBUTTON, &MouseButtonEvent{Button: button, PressType: KEY_RELEASED, Position: pos})
+}
+
+type MouseWheelEvent struct {
+	event.EventPrototype
+
+	Position vec.Coord // position of the mouse in the console
+	Delta    vec.Coord // amount scrolled. positive Y is away from the user (up), positive X is to the right.
+}
+
+func (mwe MouseWheelEvent) String() string {
+	return "Mouse Wheel Event: pos " + mwe.Position.String() + ", delta " + mwe.Delta.String()
+}
+
+// Emits mouse wheel event. Does nothing if delta is zero.
+func FireMouseWheelEvent(pos, delta vec.Coord) {
+	if !EnableMouse || delta == vec.ZERO_COORD {
+		return
+	}
+
+	event.Fire(EV_MOUSEWHEEL, &MouseWheelEvent{Position: pos, Delta: delta})
+}
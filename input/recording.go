@@ -0,0 +1,148 @@
+package input
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/log"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// InputRecord is a single recorded input event, along with the tick it was fired on. Only the fields relevant to the
+// recorded event's type are filled in.
+type InputRecord struct {
+	Tick  int    `json:"tick"`
+	Event string `json:"event"` // type of event. one of "key", "mousemove", "mousebutton", "mousewheel" or "action"
+
+	// keyboard events. PressType is also used by mouse button events
+	Key       Keycode      `json:"key,omitempty"`
+	PressType KeyPressType `json:"press,omitempty"`
+	Mods      KeyModifiers `json:"mods,omitempty"`
+	Repeat    bool         `json:"repeat,omitempty"`
+
+	// mouse events
+	Position    vec.Coord   `json:"pos,omitzero"`
+	Delta       vec.Coord   `json:"delta,omitzero"`
+	Button      MouseButton `json:"button,omitempty"`
+	DoubleClick bool        `json:"double,omitempty"`
+
+	// action events. actions are stored by name since ActionIDs depend on registration order.
+	Action string `json:"action,omitempty"`
+}
+
+// Fire fires the recorded event. The event is fired directly, bypassing the input flags (EnableMouse, etc.) and
+// without triggering actions, since any actions triggered at the time of recording were recorded as well.
+func (ir InputRecord) Fire() {
+	switch ir.Event {
+	case "key":
+		event.Fire(EV_KEYBOARD, &KeyboardEvent{Key: ir.Key, PressType: ir.PressType, Mods: ir.Mods, Repeat: ir.Repeat})
+	case "mousemove":
+		event.Fire(EV_MOUSEMOVE, &MouseMoveEvent{Position: ir.Position, Delta: ir.Delta})
+	case "mousebutton":
+		event.Fire(EV_MOUSEBUTTON, &MouseButtonEvent{Button: ir.Button, PressType: ir.PressType, Position: ir.Position, DoubleClick: ir.DoubleClick})
+	case "mousewheel":
+		event.Fire(EV_MOUSEWHEEL, &MouseWheelEvent{Position: ir.Position, Delta: ir.Delta})
+	case "action":
+		event.Fire(EV_ACTION, &ActionEvent{Action: RegisterAction(ir.Action)})
+	default:
+		log.Warning("Could not fire recorded input: unknown event type ", ir.Event)
+	}
+}
+
+// Recording is a list of input events and the ticks they were fired on, for replaying input later. Ticks are stored
+// relative to the start of the recording.
+type Recording struct {
+	records []InputRecord
+}
+
+// Add records an input event. Events that are not input events are ignored.
+func (r *Recording) Add(tick int, e event.Event) {
+	record := InputRecord{Tick: tick}
+
+	switch input_event := e.(type) {
+	case *KeyboardEvent:
+		record.Event = "key"
+		record.Key = input_event.Key
+		record.PressType = input_event.PressType
+		record.Mods = input_event.Mods
+		record.Repeat = input_event.Repeat
+	case *MouseMoveEvent:
+		record.Event = "mousemove"
+		record.Position = input_event.Position
+		record.Delta = input_event.Delta
+	case *MouseButtonEvent:
+		record.Event = "mousebutton"
+		record.Button = input_event.Button
+		record.PressType = input_event.PressType
+		record.Position = input_event.Position
+		record.DoubleClick = input_event.DoubleClick
+	case *MouseWheelEvent:
+		record.Event = "mousewheel"
+		record.Position = input_event.Position
+		record.Delta = input_event.Delta
+	case *ActionEvent:
+		record.Event = "action"
+		record.Action = input_event.Action.String()
+	default:
+		log.Debug("Could not record event ", e, ": not a recordable input event.")
+		return
+	}
+
+	r.records = append(r.records, record)
+}
+
+// Count returns the number of recorded events.
+func (r Recording) Count() int {
+	return len(r.records)
+}
+
+// At returns the ith recorded event.
+func (r Recording) At(i int) InputRecord {
+	return r.records[i]
+}
+
+// Save writes the recording to disk at path. Records are written as JSON, one per line.
+func (r Recording) Save(path string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Error("Could not save input recording: ", err)
+		return
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	encoder := json.NewEncoder(w)
+	for _, record := range r.records {
+		if err = encoder.Encode(record); err != nil {
+			log.Error("Could not save input recording: ", err)
+			return
+		}
+	}
+
+	return w.Flush()
+}
+
+// LoadRecording reads a recording previously saved with Recording.Save().
+func LoadRecording(path string) (r Recording, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Error("Could not load input recording: ", err)
+		return
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(f))
+	for decoder.More() {
+		var record InputRecord
+		if err = decoder.Decode(&record); err != nil {
+			log.Error("Could not load input recording ", path, ": ", err)
+			return Recording{}, err
+		}
+
+		r.records = append(r.records, record)
+	}
+
+	return
+}
@@ -0,0 +1,98 @@
+package headless
+
+import (
+	"github.com/bennicholls/tyumi/log"
+)
+
+// AudioSystem is a silent audio system. It keeps track of what has been loaded and played so tests can check on
+// it, but no sound is ever produced.
+type AudioSystem struct {
+	sounds []string
+	music  []string
+
+	soundsPlayed int
+	currentMusic int
+	musicPlaying bool
+	musicVolume  int
+}
+
+func (as *AudioSystem) LoadSound(path string) (platform_audio_id int, err error) {
+	as.sounds = append(as.sounds, path)
+	return len(as.sounds) - 1, nil
+}
+
+func (as *AudioSystem) UnloadSound(platform_audio_id int) {
+	if platform_audio_id < 0 || platform_audio_id >= len(as.sounds) {
+		return
+	}
+
+	as.sounds[platform_audio_id] = ""
+}
+
+func (as *AudioSystem) PlaySound(platform_audio_id, channel, volume_pct int) {
+	if platform_audio_id < 0 || platform_audio_id >= len(as.sounds) || as.sounds[platform_audio_id] == "" {
+		log.Debug("HEADLESS AUDIO: Could not play sound ", platform_audio_id, ": sound not loaded.")
+		return
+	}
+
+	as.soundsPlayed++
+}
+
+// Returns the number of sounds played since the audio system was created.
+func (as *AudioSystem) SoundsPlayed() int {
+	return as.soundsPlayed
+}
+
+func (as *AudioSystem) LoadMusic(path string) (platform_music_id int, err error) {
+	as.music = append(as.music, path)
+	return len(as.music) - 1, nil
+}
+
+func (as *AudioSystem) UnloadMusic(platform_music_id int) {
+	if platform_music_id < 0 || platform_music_id >= len(as.music) {
+		return
+	}
+
+	if as.musicPlaying && as.currentMusic == platform_music_id {
+		as.StopMusic()
+	}
+
+	as.music[platform_music_id] = ""
+}
+
+func (as *AudioSystem) PlayMusic(platform_music_id int, looping bool) {
+	if platform_music_id < 0 || platform_music_id >= len(as.music) || as.music[platform_music_id] == "" {
+		log.Debug("HEADLESS AUDIO: Could not play music ", platform_music_id, ": music not loaded.")
+		return
+	}
+
+	as.currentMusic = platform_music_id
+	as.musicPlaying = true
+}
+
+func (as *AudioSystem) SetMusicVolume(volume_pct int) {
+	as.musicVolume = volume_pct
+}
+
+func (as *AudioSystem) PauseMusic() {
+	as.musicPlaying = false
+}
+
+func (as *AudioSystem) ResumeMusic() {
+	as.musicPlaying = true
+}
+
+func (as *AudioSystem) StopMusic() {
+	as.musicPlaying = false
+}
+
+// Returns true if music is currently "playing".
+func (as *AudioSystem) MusicPlaying() bool {
+	return as.musicPlaying
+}
+
+func (as *AudioSystem) Shutdown() {
+	as.sounds = nil
+	as.music = nil
+	as.musicPlaying = false
+}
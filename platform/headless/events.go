@@ -0,0 +1,109 @@
+package headless
+
+import (
+	"github.com/bennicholls/tyumi"
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/input"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// Fires all events pushed since the last call, in the order they were pushed.
+func (p *Platform) GenerateEvents() {
+	events := p.scriptedEvents
+	p.scriptedEvents = nil
+
+	for _, fire := range events {
+		fire()
+	}
+}
+
+// PendingEvents returns the number of scripted events waiting to be fired.
+func (p *Platform) PendingEvents() int {
+	return len(p.scriptedEvents)
+}
+
+// PushKeyPress queues a key press event.
+func (p *Platform) PushKeyPress(key input.Keycode, mods ...input.KeyModifiers) {
+	p.scriptedEvents = append(p.scriptedEvents, func() {
+		input.FireKeyPressEvent(key, mods...)
+	})
+}
+
+// PushKeyRelease queues a key release event.
+func (p *Platform) PushKeyRelease(key input.Keycode, mods ...input.KeyModifiers) {
+	p.scriptedEvents = append(p.scriptedEvents, func() {
+		input.FireKeyReleaseEvent(key, mods...)
+	})
+}
+
+// PushKeyRepeat queues a key repeat event, as if the key was being held down.
+func (p *Platform) PushKeyRepeat(key input.Keycode, mods ...input.KeyModifiers) {
+	p.scriptedEvents = append(p.scriptedEvents, func() {
+		input.FireKeyRepeatEvent(key, mods...)
+	})
+}
+
+// PushKeyTap queues a key press immediately followed by a key release.
+func (p *Platform) PushKeyTap(key input.Keycode, mods ...input.KeyModifiers) {
+	p.PushKeyPress(key, mods...)
+	p.PushKeyRelease(key, mods...)
+}
+
+// PushMouseMove queues a mouse move event to the provided cell of the console. Moves to the cell the mouse is
+// already in are ignored, same as a real platform.
+func (p *Platform) PushMouseMove(pos vec.Coord) {
+	p.scriptedEvents = append(p.scriptedEvents, func() {
+		if pos == p.mouse_position {
+			return
+		}
+
+		input.FireMouseMoveEvent(pos, pos.Subtract(p.mouse_position))
+		p.mouse_position = pos
+	})
+}
+
+// PushMouseButtonPress queues a mouse button press at the current mouse position. Use PushMouseMove() first to move
+// the mouse where you want to click.
+func (p *Platform) PushMouseButtonPress(button input.MouseButton, double_click bool) {
+	p.scriptedEvents = append(p.scriptedEvents, func() {
+		input.FireMouseButtonPressEvent(button, p.mouse_position, double_click)
+	})
+}
+
+// PushMouseButtonRelease queues a mouse button release at the current mouse position.
+func (p *Platform) PushMouseButtonRelease(button input.MouseButton) {
+	p.scriptedEvents = append(p.scriptedEvents, func() {
+		input.FireMouseButtonReleaseEvent(button, p.mouse_position)
+	})
+}
+
+// PushMouseClick queues a mouse move to pos, followed by a press and release of the provided button.
+func (p *Platform) PushMouseClick(pos vec.Coord, button input.MouseButton) {
+	p.PushMouseMove(pos)
+	p.PushMouseButtonPress(button, false)
+	p.PushMouseButtonRelease(button)
+}
+
+// PushMouseWheel queues a mouse wheel event at the current mouse position.
+func (p *Platform) PushMouseWheel(delta vec.Coord) {
+	p.scriptedEvents = append(p.scriptedEvents, func() {
+		input.FireMouseWheelEvent(p.mouse_position, delta)
+	})
+}
+
+// PushQuit queues a quit event, as if the user closed the window. Useful for ending a call to tyumi.Run().
+func (p *Platform) PushQuit() {
+	p.scriptedEvents = append(p.scriptedEvents, func() {
+		event.Fire(tyumi.EV_QUIT)
+	})
+}
+
+// PushFunc queues an arbitrary function to be run when events are generated. Use this for anything the other
+// Push functions don't cover.
+func (p *Platform) PushFunc(f func()) {
+	if f == nil {
+		return
+	}
+
+	p.scriptedEvents = append(p.scriptedEvents, f)
+}
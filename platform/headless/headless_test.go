@@ -0,0 +1,355 @@
+package headless_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bennicholls/tyumi"
+	"github.com/bennicholls/tyumi/gfx/ui"
+	"github.com/bennicholls/tyumi/input"
+	"github.com/bennicholls/tyumi/platform/headless"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// Tyumi's engine state is global, so all tests in this file share the same platform and scene. They are set up by
+// the first test that needs them.
+var (
+	platform    *headless.Platform
+	keysPressed int
+	lastClick   *input.MouseButtonEvent
+
+	button        *ui.Button
+	buttonPresses int
+	buttonHovered bool
+
+	list *ui.List
+)
+
+func setupEngine(t *testing.T) {
+	t.Helper()
+
+	if platform != nil {
+		return
+	}
+
+	platform = headless.NewPlatform()
+	if err := tyumi.SetPlatform(platform); err != nil {
+		t.Fatal("Could not set headless platform: ", err)
+	}
+
+	tyumi.InitConsole("Headless Test", vec.Dims{40, 10}, "", "")
+
+	scene := new(tyumi.Scene)
+	scene.Init()
+	scene.Window().AddChild(ui.NewTextbox(vec.Dims{20, 1}, vec.Coord{0, 0}, 0, "Hello Headless", ui.ALIGN_LEFT))
+	button = ui.NewButton(vec.Dims{10, 1}, vec.Coord{0, 5}, 0, "Button", func() { buttonPresses++ })
+	button.OnMouseEnter = func() { buttonHovered = true }
+	button.OnMouseLeave = func() { buttonHovered = false }
+	scene.Window().AddChild(button)
+
+	list = ui.NewList(vec.Dims{10, 3}, vec.Coord{20, 2}, 0)
+	list.InsertText(ui.ALIGN_LEFT, "Zero", "One", "Two")
+	scene.Window().AddChild(list)
+
+	scene.SetKeypressHandler(func(key_event *input.KeyboardEvent) bool {
+		if key_event.PressType == input.KEY_PRESSED && key_event.Key == input.K_SPACE {
+			keysPressed++
+			return true
+		}
+		return false
+	})
+
+	scene.SetMouseButtonHandler(func(mouse_event *input.MouseButtonEvent) bool {
+		if mouse_event.PressType == input.KEY_PRESSED {
+			lastClick = mouse_event
+		}
+		return true
+	})
+
+	tyumi.SetInitialScene(scene)
+}
+
+func TestHeadlessStep(t *testing.T) {
+	setupEngine(t)
+
+	startTick := tyumi.GetTick()
+	keysPressed = 0
+
+	platform.PushKeyTap(input.K_SPACE)
+	for range 3 {
+		tyumi.Step(20 * time.Millisecond)
+		if delta := tyumi.GetFrameDelta(); delta != 20*time.Millisecond {
+			t.Errorf("Expected frame delta of 20ms, got %v", delta)
+		}
+	}
+
+	if ticks := tyumi.GetTick() - startTick; ticks != 3 {
+		t.Errorf("Expected 3 ticks to be run, got %d", ticks)
+	}
+
+	if keysPressed != 1 {
+		t.Errorf("Expected 1 keypress to reach scene, got %d", keysPressed)
+	}
+
+	if !tyumi.IsRunning() {
+		t.Errorf("Engine stopped running unexpectedly.")
+	}
+}
+
+func TestHeadlessRecordReplay(t *testing.T) {
+	setupEngine(t)
+
+	path := t.TempDir() + "/input.rec"
+	keysPressed = 0
+
+	tyumi.StartRecording()
+	tyumi.Step(time.Millisecond)
+	platform.PushKeyTap(input.K_SPACE)
+	tyumi.Step(time.Millisecond)
+	if err := tyumi.StopRecording(path); err != nil {
+		t.Fatal("Could not save recording: ", err)
+	}
+
+	if err := tyumi.StartReplay(path); err != nil {
+		t.Fatal("Could not load recording: ", err)
+	}
+
+	tyumi.Step(time.Millisecond)
+	if keysPressed != 1 {
+		t.Errorf("Replayed key fired on the wrong tick.")
+	}
+
+	tyumi.Step(time.Millisecond)
+	if keysPressed != 2 {
+		t.Errorf("Expected 2 keypresses after replay, got %d", keysPressed)
+	}
+
+	if tyumi.IsReplaying() {
+		t.Errorf("Replay did not finish.")
+	}
+}
+
+func TestHeadlessMouse(t *testing.T) {
+	setupEngine(t)
+
+	input.EnableMouse = true
+	defer func() { input.EnableMouse = false }()
+
+	lastClick = nil
+	platform.PushMouseClick(vec.Coord{5, 3}, input.MOUSE_RIGHT)
+	tyumi.Step(time.Millisecond)
+
+	if lastClick == nil {
+		t.Fatal("Mouse click did not reach scene.")
+	}
+
+	if lastClick.Button != input.MOUSE_RIGHT || lastClick.Position != (vec.Coord{5, 3}) {
+		t.Errorf("Wrong mouse click received: %s", lastClick)
+	}
+
+	buttonPresses = 0
+	platform.PushMouseClick(vec.Coord{3, 5}, input.MOUSE_LEFT)
+	tyumi.Step(time.Millisecond)
+
+	if !buttonHovered || !button.IsHovered() {
+		t.Errorf("Button not hovered after mouse moved over it.")
+	}
+
+	if buttonPresses != 1 {
+		t.Errorf("Expected button to be clicked once, got %d", buttonPresses)
+	}
+
+	// press on the button but release elsewhere, which shouldn't click.
+	platform.PushMouseButtonPress(input.MOUSE_LEFT, false)
+	platform.PushMouseMove(vec.Coord{3, 8})
+	platform.PushMouseButtonRelease(input.MOUSE_LEFT)
+	tyumi.Step(time.Millisecond)
+
+	if buttonHovered {
+		t.Errorf("Button still hovered after mouse left it.")
+	}
+
+	if buttonPresses != 1 {
+		t.Errorf("Button clicked even though mouse was released elsewhere.")
+	}
+}
+
+func TestHeadlessListClick(t *testing.T) {
+	setupEngine(t)
+
+	input.EnableMouse = true
+	defer func() { input.EnableMouse = false }()
+
+	list.DisableSelection()
+	platform.PushMouseClick(vec.Coord{21, 3}, input.MOUSE_LEFT)
+	tyumi.Step(time.Millisecond)
+
+	if list.GetSelectionIndex() != -1 {
+		t.Errorf("Clicking selected an item even though selection is disabled.")
+	}
+
+	list.EnableSelection()
+	if list.GetSelectionIndex() != 0 {
+		t.Errorf("Enabling selection should select the first item, got %d", list.GetSelectionIndex())
+	}
+
+	platform.PushMouseClick(vec.Coord{21, 4}, input.MOUSE_LEFT)
+	tyumi.Step(time.Millisecond)
+
+	if list.GetSelectionIndex() != 2 {
+		t.Errorf("Clicking the third item selected item %d", list.GetSelectionIndex())
+	}
+
+	platform.PushMouseClick(vec.Coord{21, 3}, input.MOUSE_RIGHT)
+	tyumi.Step(time.Millisecond)
+
+	if list.GetSelectionIndex() != 2 {
+		t.Errorf("Right clicking changed the selection.")
+	}
+
+	list.DisableSelection()
+}
+
+func TestHeadlessSceneStack(t *testing.T) {
+	setupEngine(t)
+
+	shutdown := false
+	menu := new(tyumi.Scene)
+	menu.Init()
+	menu.Window().AddChild(ui.NewTextbox(vec.Dims{20, 1}, vec.Coord{0, 0}, 0, "Pause Menu", ui.ALIGN_LEFT))
+	menu.SetKeypressHandler(func(key_event *input.KeyboardEvent) bool {
+		if key_event.PressType == input.KEY_PRESSED && key_event.Key == input.K_ESCAPE {
+			shutdown = true
+			tyumi.PopScene(tyumi.SceneTransition{Style: tyumi.TRANSITION_SLIDE_DOWN, Duration: 50 * time.Millisecond})
+			return true
+		}
+		return false
+	})
+
+	tyumi.PushScene(menu, tyumi.SceneTransition{Style: tyumi.TRANSITION_FADE, Duration: 50 * time.Millisecond})
+	for range 10 {
+		tyumi.Step(10 * time.Millisecond)
+	}
+
+	if tyumi.SceneStackDepth() != 2 {
+		t.Fatalf("Expected 2 scenes on the stack, got %d", tyumi.SceneStackDepth())
+	}
+
+	if row := platform.Renderer().RowText(0); !strings.Contains(row, "Pause Menu") {
+		t.Errorf("Pushed scene not rendered. Got %q", row)
+	}
+
+	// the suspended scene should not receive input
+	keysPressed = 0
+	platform.PushKeyTap(input.K_SPACE)
+	platform.PushKeyTap(input.K_ESCAPE)
+	for range 10 {
+		tyumi.Step(10 * time.Millisecond)
+	}
+
+	if keysPressed != 0 {
+		t.Errorf("Suspended scene received input.")
+	}
+
+	if !shutdown || tyumi.SceneStackDepth() != 1 {
+		t.Fatalf("Scene was not popped.")
+	}
+
+	if row := platform.Renderer().RowText(0); !strings.Contains(row, "Hello Headless") {
+		t.Errorf("Resumed scene not rendered. Got %q", row)
+	}
+
+	platform.PushKeyTap(input.K_SPACE)
+	tyumi.Step(10 * time.Millisecond)
+
+	if keysPressed != 1 {
+		t.Errorf("Resumed scene did not receive input.")
+	}
+}
+
+func TestHeadlessTimers(t *testing.T) {
+	setupEngine(t)
+
+	timerScene := new(tyumi.Scene)
+	timerScene.Init()
+	tyumi.PushScene(timerScene)
+	tyumi.Step(10 * time.Millisecond)
+
+	fired := 0
+	timer := timerScene.CreateRepeatingTimer(20*time.Millisecond, func() { fired++ })
+	for range 10 {
+		tyumi.Step(10 * time.Millisecond)
+	}
+
+	if fired != 5 {
+		t.Errorf("Expected repeating timer to fire 5 times, got %d", fired)
+	}
+
+	timer.Pause()
+	tyumi.Step(time.Second)
+	if fired != 5 {
+		t.Errorf("Paused timer fired.")
+	}
+
+	// a large frame delta should fire once for each interval that passed
+	timer.Resume()
+	tyumi.Step(50 * time.Millisecond)
+	if fired != 7 {
+		t.Errorf("Expected timer to catch up to 7 fires, got %d", fired)
+	}
+
+	timer.Cancel()
+	tyumi.Step(time.Second)
+	if fired != 7 {
+		t.Errorf("Cancelled timer fired.")
+	}
+
+	tyumi.PopScene()
+	tyumi.Step(10 * time.Millisecond)
+
+	// timers that aren't managed by a scene can be reset and reused once they're done
+	oneShot := tyumi.Timer{Ticks: 2, TimerFunction: func() { fired++ }}
+	oneShot.Update(0)
+	oneShot.Update(0)
+	if !oneShot.Done() || fired != 8 {
+		t.Fatalf("One shot timer didn't fire.")
+	}
+
+	oneShot.Reset()
+	oneShot.Update(0)
+	oneShot.Update(0)
+	if fired != 9 {
+		t.Errorf("Reset timer didn't fire again.")
+	}
+}
+
+func TestHeadlessRun(t *testing.T) {
+	setupEngine(t)
+
+	keysPressed = 0
+
+	platform.PushKeyTap(input.K_SPACE)
+	platform.PushQuit()
+	tyumi.Run()
+
+	if keysPressed != 1 {
+		t.Errorf("Expected 1 keypress to reach scene, got %d", keysPressed)
+	}
+
+	if platform.PendingEvents() != 0 {
+		t.Errorf("Scripted events were not consumed.")
+	}
+
+	if platform.Renderer().Frames() == 0 {
+		t.Fatal("No frames rendered.")
+	}
+
+	if row := platform.Renderer().RowText(0); !strings.Contains(row, "Hello Headless") {
+		t.Errorf("Rendered frame missing text. Got %q", row)
+	}
+
+	if tyumi.IsRunning() {
+		t.Errorf("Engine still running after quit.")
+	}
+}
@@ -0,0 +1,58 @@
+// Package headless is a Tyumi platform that doesn't touch any real hardware. The console is rendered into an
+// in-memory canvas and input is supplied by pushing scripted events into the platform, which are then fired during
+// the next call to GenerateEvents(). Useful for running scenes, dialogs, and UI trees in tests or on build machines
+// without a display.
+package headless
+
+import (
+	"github.com/bennicholls/tyumi"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+type Platform struct {
+	renderer Renderer
+	audio    AudioSystem
+
+	title string
+
+	scriptedEvents []func() // events pushed by the user, fired on the next call to GenerateEvents()
+	mouse_position vec.Coord
+}
+
+func (p *Platform) Init() (err error) {
+	return
+}
+
+func (p *Platform) ChangeTitle(title string) {
+	p.title = title
+}
+
+// Title returns the most recent title set for the program.
+func (p *Platform) Title() string {
+	return p.title
+}
+
+func (p *Platform) GetRenderer() tyumi.Renderer {
+	return &p.renderer
+}
+
+// Returns the headless renderer directly, for inspecting rendered output.
+func (p *Platform) Renderer() *Renderer {
+	return &p.renderer
+}
+
+func (p *Platform) GetAudioSystem() tyumi.AudioSystem {
+	return &p.audio
+}
+
+func (p *Platform) Shutdown() {
+	p.renderer.Cleanup()
+	p.audio.Shutdown()
+	p.scriptedEvents = nil
+}
+
+// Creates a headless platform for use by Tyumi. Pass this into tyumi.SetPlatform()
+func NewPlatform() *Platform {
+	headless_platform := new(Platform)
+	return headless_platform
+}
@@ -0,0 +1,160 @@
+package headless
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bennicholls/tyumi/gfx"
+	"github.com/bennicholls/tyumi/gfx/col"
+	"github.com/bennicholls/tyumi/log"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// Renderer "renders" the console to an in-memory buffer of cells. The most recently rendered frame can be inspected
+// with Frame(), GetCell() and friends.
+type Renderer struct {
+	console   *gfx.Canvas
+	frame     []gfx.Visuals // copy of the console's cells as of the most recent render
+	frameSize vec.Dims
+
+	forceRedraw bool
+	fullscreen  bool
+	debugModes  map[string]bool
+
+	frames int // frames rendered. NOTE: this can differ from engine.tick since the renderer may not render every tick
+
+	clearColour col.Colour
+
+	ready bool
+}
+
+func (r *Renderer) Setup(console *gfx.Canvas, glyphPath, fontPath, title string) (err error) {
+	if console == nil || !console.Ready() {
+		log.Error("HEADLESS RENDERER: Could not setup renderer, console not initialized.")
+		return
+	}
+
+	r.console = console
+	r.frameSize = console.Size()
+	r.frame = make([]gfx.Visuals, r.frameSize.Area())
+	r.debugModes = make(map[string]bool)
+	r.forceRedraw = true
+	r.ready = true
+
+	return
+}
+
+func (r *Renderer) Ready() bool {
+	return r.ready
+}
+
+func (r *Renderer) Cleanup() {
+	r.ready = false
+}
+
+// Fonts are never loaded by the headless renderer, so this does nothing.
+func (r *Renderer) ChangeFonts(glyphPath, fontPath string) (err error) {
+	return
+}
+
+func (r *Renderer) SetFullscreen(enable bool) {
+	r.fullscreen = enable
+}
+
+func (r *Renderer) ToggleFullscreen() {
+	r.fullscreen = !r.fullscreen
+}
+
+func (r *Renderer) IsFullscreen() bool {
+	return r.fullscreen
+}
+
+func (r *Renderer) SetClearColour(colour col.Colour) {
+	r.clearColour = colour
+}
+
+func (r *Renderer) ForceRedraw() {
+	r.forceRedraw = true
+}
+
+func (r *Renderer) ToggleDebugMode(m string) {
+	if r.debugModes == nil {
+		r.debugModes = make(map[string]bool)
+	}
+
+	r.debugModes[m] = !r.debugModes[m]
+}
+
+// Copies any changed cells from the console into the rendered frame.
+func (r *Renderer) Render() {
+	if !r.ready {
+		return
+	}
+
+	if r.frameSize != r.console.Size() {
+		r.frameSize = r.console.Size()
+		r.frame = make([]gfx.Visuals, r.frameSize.Area())
+		r.forceRedraw = true
+	}
+
+	if r.forceRedraw || r.console.Dirty() {
+		for cell, cursor := range r.console.EachCell() {
+			if r.forceRedraw || r.console.IsDirtyAt(cursor) {
+				r.frame[cursor.ToIndex(r.frameSize.W)] = cell
+			}
+		}
+	}
+
+	r.console.Clean()
+	r.forceRedraw = false
+	r.frames++
+}
+
+// Frames returns the number of frames rendered.
+func (r *Renderer) Frames() int {
+	return r.frames
+}
+
+// Frame returns a copy of the cells of the most recently rendered frame, along with the size of the frame.
+func (r *Renderer) Frame() ([]gfx.Visuals, vec.Dims) {
+	return slices.Clone(r.frame), r.frameSize
+}
+
+// GetCell returns the visuals rendered at pos in the most recent frame. If pos is out of bounds, returns empty visuals.
+func (r *Renderer) GetCell(pos vec.Coord) (cell gfx.Visuals) {
+	if !pos.IsInside(r.frameSize) {
+		return
+	}
+
+	return r.frame[pos.ToIndex(r.frameSize.W)]
+}
+
+// RowText returns the characters rendered on row y of the most recent frame as a string. Text cells contribute both
+// of their characters, glyph cells contribute their glyph number as a byte. Empty cells are reported as spaces.
+// Mostly useful for checking that some text made it to the screen.
+func (r *Renderer) RowText(y int) string {
+	var b strings.Builder
+	for x := range r.frameSize.W {
+		cell := r.GetCell(vec.Coord{x, y})
+		switch cell.Mode {
+		case gfx.DRAW_TEXT:
+			for _, char := range cell.Chars {
+				if char == gfx.TEXT_NONE || char == gfx.TEXT_DEFAULT {
+					b.WriteByte(' ')
+				} else {
+					b.WriteByte(char)
+				}
+			}
+		case gfx.DRAW_GLYPH:
+			if cell.Glyph == gfx.GLYPH_NONE {
+				b.WriteByte(' ')
+			} else {
+				b.WriteByte(byte(cell.Glyph))
+			}
+		default:
+			b.WriteByte(' ')
+		}
+	}
+
+	return b.String()
+}
@@ -58,7 +58,24 @@ eventLoop:
 		case *sdl.MouseMotionEvent:
 			new_mouse_pos = vec.Coord{int(e.X) / p.renderer.tileSize, int(e.Y) / p.renderer.tileSize}
 		case *sdl.MouseButtonEvent:
-			continue
+			button, ok := mouseButtonMap[e.Button]
+			if !ok {
+				continue
+			}
+
+			pos := vec.Coord{int(e.X) / p.renderer.tileSize, int(e.Y) / p.renderer.tileSize}
+			switch e.State {
+			case sdl.PRESSED:
+				input.FireMouseButtonPressEvent(button, pos, e.Clicks == 2)
+			case sdl.RELEASED:
+				input.FireMouseButtonReleaseEvent(button, pos)
+			}
+		case *sdl.MouseWheelEvent:
+			delta := vec.Coord{int(e.X), int(e.Y)}
+			if e.Direction == sdl.MOUSEWHEEL_FLIPPED { // natural scrolling reports the deltas backwards
+				delta = delta.Scale(-1)
+			}
+			input.FireMouseWheelEvent(new_mouse_pos, delta)
 		}
 	}
 
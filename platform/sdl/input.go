@@ -5,6 +5,14 @@ import (
 	"github.com/veandco/go-sdl2/sdl"
 )
 
+var mouseButtonMap = map[uint8]input.MouseButton{
+	sdl.BUTTON_LEFT:   input.MOUSE_LEFT,
+	sdl.BUTTON_MIDDLE: input.MOUSE_MIDDLE,
+	sdl.BUTTON_RIGHT:  input.MOUSE_RIGHT,
+	sdl.BUTTON_X1:     input.MOUSE_X1,
+	sdl.BUTTON_X2:     input.MOUSE_X2,
+}
+
 var keycodemap = map[sdl.Keycode]input.Keycode{
 	sdl.K_UNKNOWN:      input.K_UNKNOWN,
 	sdl.K_RETURN:       input.K_RETURN,
@@ -58,7 +58,30 @@ eventLoop:
 			mouseEvent := sdlEvent.Motion()
 			new_mouse_pos = vec.Coord{int(mouseEvent.X) / p.renderer.tileSize, int(mouseEvent.Y) / p.renderer.tileSize}
 		case sdl.EventMouseButtonDown, sdl.EventMouseButtonUp:
-			continue
+			mouseEvent := sdlEvent.Button()
+			button, ok := mouseButtonMap[mouseEvent.Button]
+			if !ok {
+				continue
+			}
+
+			pos := vec.Coord{int(mouseEvent.X) / p.renderer.tileSize, int(mouseEvent.Y) / p.renderer.tileSize}
+			if mouseEvent.Down {
+				input.FireMouseButtonPressEvent(button, pos, mouseEvent.Clicks == 2)
+			} else {
+				input.FireMouseButtonReleaseEvent(button, pos)
+			}
+		case sdl.EventMouseWheel:
+			// sdl3 reports scrolling as floats (for smooth scrolling trackpads, etc.) so we accumulate partial scrolls
+			// until they add up to a whole step.
+			wheelEvent := sdlEvent.Wheel()
+			p.wheel_remainder[0] += wheelEvent.X
+			p.wheel_remainder[1] += wheelEvent.Y
+			delta := vec.Coord{int(p.wheel_remainder[0]), int(p.wheel_remainder[1])}
+			p.wheel_remainder[0] -= float32(delta.X)
+			p.wheel_remainder[1] -= float32(delta.Y)
+			if delta != vec.ZERO_COORD {
+				input.FireMouseWheelEvent(new_mouse_pos, delta)
+			}
 		}
 	}
 
@@ -67,4 +90,3 @@ eventLoop:
 		p.mouse_position = new_mouse_pos
 	}
 }
-
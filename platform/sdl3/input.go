@@ -5,6 +5,14 @@ import (
 	"github.com/jupiterrider/purego-sdl3/sdl"
 )
 
+var mouseButtonMap = map[uint8]input.MouseButton{
+	sdl.ButtonLeft:   input.MOUSE_LEFT,
+	sdl.ButtonMiddle: input.MOUSE_MIDDLE,
+	sdl.ButtonRight:  input.MOUSE_RIGHT,
+	sdl.ButtonX1:     input.MOUSE_X1,
+	sdl.ButtonX2:     input.MOUSE_X2,
+}
+
 var keycodemap = map[sdl.Keycode]input.Keycode{
 	sdl.KeycodeUnknown:       input.K_UNKNOWN,
 	sdl.KeycodeReturn:        input.K_RETURN,
@@ -11,7 +11,8 @@ type Platform struct {
 	renderer Renderer
 	audio    tyumi.AudioSystem
 
-	mouse_position vec.Coord
+	mouse_position  vec.Coord
+	wheel_remainder [2]float32 // partial mouse wheel scrolling not yet reported
 }
 
 func (p *Platform) Init() (err error) {
@@ -0,0 +1,45 @@
+package terminal
+
+import (
+	"github.com/bennicholls/tyumi/gfx"
+)
+
+// Since each cell is 2 terminal columns wide, glyphs only fill the left half of the cell. Glyphs that connect to
+// the right (box drawing lines) or fill the whole cell (blocks) are extended into the right column so lines and
+// solid areas stay continuous. Everything else gets a blank.
+var glyphFillers = map[rune]rune{
+	'─': '─', '┌': '─', '└': '─', '├': '─', '┬': '─', '┴': '─', '┼': '─',
+	'╓': '─', '╙': '─', '╟': '─', '╥': '─', '╨': '─', '╫': '─',
+	'═': '═', '╔': '═', '╚': '═', '╠': '═', '╦': '═', '╩': '═', '╬': '═',
+	'╒': '═', '╘': '═', '╞': '═', '╤': '═', '╧': '═', '╪': '═',
+	'░': '░', '▒': '▒', '▓': '▓', '█': '█', '▄': '▄', '▀': '▀',
+}
+
+// Glyph 0 and 255 are blanks. Glyphs are always laid out as CP437 (see the GLYPH_ constants), whatever code page is
+// being used for text.
+func glyphToRune(glyph gfx.Glyph) rune {
+	switch glyph {
+	case 0, 255:
+		return ' '
+	}
+
+	return gfx.CP437.Rune(uint8(glyph))
+}
+
+func glyphFiller(r rune) rune {
+	if filler, ok := glyphFillers[r]; ok {
+		return filler
+	}
+
+	return ' '
+}
+
+// Text characters are indices into the text font, so they're converted back using the active code page.
+func textToRune(char uint8) rune {
+	switch char {
+	case 0, gfx.TEXT_NONE, gfx.TEXT_DEFAULT:
+		return ' '
+	}
+
+	return gfx.GetCodePage().Rune(char)
+}
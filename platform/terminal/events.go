@@ -0,0 +1,38 @@
+package terminal
+
+import (
+	"github.com/bennicholls/tyumi"
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/input"
+)
+
+// terminal event processor. Terminals only report key presses, so each key press is followed immediately by a
+// matching key release. CTRL+C quits, since that's what people expect in a terminal and there's no window to close.
+func (p *Platform) GenerateEvents() {
+	if p.renderer.ready {
+		p.renderer.updateTermSize()
+	}
+
+	for {
+		select {
+		case chunk, ok := <-p.inputChunks:
+			if !ok { // stdin closed, nothing more is coming.
+				event.Fire(tyumi.EV_QUIT)
+				p.inputChunks = nil
+				return
+			}
+
+			for _, key := range parseInput(chunk) {
+				if key.key == input.K_c && key.mods == input.KEYMOD_CTRL {
+					event.Fire(tyumi.EV_QUIT)
+					return //don't care about other input events if we're quitting
+				}
+
+				input.FireKeyPressEvent(key.key, key.mods)
+				input.FireKeyReleaseEvent(key.key, key.mods)
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package terminal
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bennicholls/tyumi/input"
+)
+
+const (
+	key_ESC = 0x1b
+	key_DEL = 0x7f
+)
+
+// a keypress decoded from terminal input.
+type keyPress struct {
+	key  input.Keycode
+	mods input.KeyModifiers
+}
+
+// reads raw bytes from the terminal and sends them out in chunks as they arrive. escape sequences are sent by the
+// terminal all at once, so each sequence should arrive in a single chunk.
+func readInput(r io.Reader, chunks chan<- []byte) {
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			chunks <- chunk
+		}
+
+		if err != nil {
+			close(chunks)
+			return
+		}
+	}
+}
+
+// printable ascii characters and the keys that produce them. uppercase letters are handled separately.
+var asciiKeys = map[byte]input.Keycode{
+	' ': input.K_SPACE, '!': input.K_EXCLAIM, '"': input.K_QUOTEDBL, '#': input.K_HASH, '$': input.K_DOLLAR,
+	'%': input.K_PERCENT, '&': input.K_AMPERSAND, '\'': input.K_QUOTE, '(': input.K_LEFTPAREN, ')': input.K_RIGHTPAREN,
+	'*': input.K_ASTERISK, '+': input.K_PLUS, ',': input.K_COMMA, '-': input.K_MINUS, '.': input.K_PERIOD,
+	'/': input.K_SLASH, '0': input.K_0, '1': input.K_1, '2': input.K_2, '3': input.K_3, '4': input.K_4, '5': input.K_5,
+	'6': input.K_6, '7': input.K_7, '8': input.K_8, '9': input.K_9, ':': input.K_COLON, ';': input.K_SEMICOLON,
+	'<': input.K_LESS, '=': input.K_EQUALS, '>': input.K_GREATER, '?': input.K_QUESTION, '@': input.K_AT,
+	'[': input.K_LEFTBRACKET, '\\': input.K_BACKSLASH, ']': input.K_RIGHTBRACKET, '^': input.K_CARET,
+	'_': input.K_UNDERSCORE, '`': input.K_BACKQUOTE,
+}
+
+// keys for CSI sequences of the form ESC [ <final>, and SS3 sequences of the form ESC O <final>
+var csiFinalKeys = map[byte]input.Keycode{
+	'A': input.K_UP, 'B': input.K_DOWN, 'C': input.K_RIGHT, 'D': input.K_LEFT, 'H': input.K_HOME, 'F': input.K_END,
+	'P': input.K_F1, 'Q': input.K_F2, 'R': input.K_F3, 'S': input.K_F4,
+}
+
+// keypad keys in application keypad mode, which are only sent as SS3 sequences. ESC [ M is the start of an X10 mouse
+// report, so it mustn't be read as keypad enter.
+var ss3FinalKeys = map[byte]input.Keycode{
+	'M': input.K_KP_ENTER, 'p': input.K_KP_0, 'q': input.K_KP_1, 'r': input.K_KP_2, 's': input.K_KP_3, 't': input.K_KP_4, 'u': input.K_KP_5,
+	'v': input.K_KP_6, 'w': input.K_KP_7, 'x': input.K_KP_8, 'y': input.K_KP_9, 'n': input.K_KP_PERIOD,
+	'j': input.K_KP_MULTIPLY, 'k': input.K_KP_PLUS, 'm': input.K_KP_MINUS, 'o': input.K_KP_DIVIDE,
+}
+
+// keys for CSI sequences of the form ESC [ <number> ~
+var csiTildeKeys = map[int]input.Keycode{
+	1: input.K_HOME, 2: input.K_INSERT, 3: input.K_DELETE, 4: input.K_END, 5: input.K_PAGEUP, 6: input.K_PAGEDOWN,
+	7: input.K_HOME, 8: input.K_END, 11: input.K_F1, 12: input.K_F2, 13: input.K_F3, 14: input.K_F4, 15: input.K_F5,
+	17: input.K_F6, 18: input.K_F7, 19: input.K_F8, 20: input.K_F9, 21: input.K_F10, 23: input.K_F11, 24: input.K_F12,
+}
+
+// decodes a chunk of terminal input into keypresses.
+func parseInput(data []byte) (keys []keyPress) {
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case b == key_ESC:
+			if i+1 == len(data) || data[i+1] == key_ESC { // lone escape
+				keys = append(keys, keyPress{key: input.K_ESCAPE})
+				continue
+			}
+
+			if next := data[i+1]; next == '[' || next == 'O' {
+				key, length := parseEscapeSequence(data[i+2:], next == 'O')
+				i += 1 + length
+				if key.key != input.K_UNKNOWN {
+					keys = append(keys, key)
+				}
+				continue
+			}
+
+			// ESC followed by a regular character is how terminals send ALT+key
+			if key, ok := decodeByte(data[i+1]); ok {
+				key.mods |= input.KEYMOD_ALT
+				keys = append(keys, key)
+			}
+			i++
+		case b >= 0x80: // utf-8 multibyte characters. no keycodes for these, so skip the whole character
+			for i+1 < len(data) && data[i+1]&0xC0 == 0x80 {
+				i++
+			}
+		default:
+			if key, ok := decodeByte(b); ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return
+}
+
+// decodes a single byte of regular (non-escape sequence) terminal input.
+func decodeByte(b byte) (key keyPress, ok bool) {
+	switch {
+	case b == '\r' || b == '\n':
+		key.key = input.K_RETURN
+	case b == '\t':
+		key.key = input.K_TAB
+	case b == key_DEL || b == 0x08:
+		key.key = input.K_BACKSPACE
+	case b == 0:
+		key = keyPress{input.K_SPACE, input.KEYMOD_CTRL}
+	case b >= 1 && b <= 26: // CTRL+A through CTRL+Z
+		key = keyPress{input.K_a + input.Keycode(b-1), input.KEYMOD_CTRL}
+	case b >= 'a' && b <= 'z':
+		key.key = input.K_a + input.Keycode(b-'a')
+	case b >= 'A' && b <= 'Z':
+		key = keyPress{input.K_a + input.Keycode(b-'A'), input.KEYMOD_SHIFT}
+	default:
+		if k, found := asciiKeys[b]; found {
+			key.key = k
+		} else {
+			return
+		}
+	}
+
+	return key, true
+}
+
+// parses the body of a CSI or SS3 sequence (everything after ESC [ or ESC O). returns the decoded key and the number
+// of bytes consumed. unrecognized sequences produce K_UNKNOWN.
+func parseEscapeSequence(seq []byte, ss3 bool) (key keyPress, length int) {
+	// parameters are digits and semicolons, followed by a single final byte
+	for length < len(seq) && (seq[length] >= '0' && seq[length] <= '9' || seq[length] == ';') {
+		length++
+	}
+
+	if length == len(seq) {
+		return
+	}
+
+	final := seq[length]
+	params := strings.Split(string(seq[:length]), ";")
+	length++
+
+	switch final {
+	case '~':
+		if n, err := strconv.Atoi(params[0]); err == nil {
+			key.key = csiTildeKeys[n]
+		}
+	case 'Z': // shift-tab
+		key = keyPress{input.K_TAB, input.KEYMOD_SHIFT}
+	case 'M':
+		if ss3 {
+			key.key = ss3FinalKeys[final]
+		} else if length == 1 {
+			// X10 mouse report: ESC [ M followed by 3 bytes of button and position. we don't do mouse input, so skip it.
+			length = min(length+3, len(seq))
+		}
+	default:
+		if k, ok := ss3FinalKeys[final]; ok && ss3 {
+			key.key = k
+		} else {
+			key.key = csiFinalKeys[final]
+		}
+	}
+
+	// modifiers are sent as a second parameter, encoded as 1 + (shift | alt<<1 | ctrl<<2)
+	if len(params) > 1 {
+		if m, err := strconv.Atoi(params[1]); err == nil && m > 1 {
+			m -= 1
+			if m&1 != 0 {
+				key.mods |= input.KEYMOD_SHIFT
+			}
+			if m&2 != 0 {
+				key.mods |= input.KEYMOD_ALT
+			}
+			if m&4 != 0 {
+				key.mods |= input.KEYMOD_CTRL
+			}
+		}
+	}
+
+	return
+}
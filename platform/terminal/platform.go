@@ -0,0 +1,63 @@
+// Package terminal is a Tyumi platform that renders to a terminal using true-colour ANSI escape codes and reads
+// keyboard input from stdin in raw mode. Each console cell is drawn as 2 terminal columns, which keeps cells roughly
+// square and lets half-width text draw 2 characters per cell just like the SDL platform. Good for playing over SSH,
+// the way roguelikes were meant to be played.
+package terminal
+
+import (
+	"os"
+	"sync"
+
+	"github.com/bennicholls/tyumi"
+	"github.com/bennicholls/tyumi/gfx"
+	"github.com/bennicholls/tyumi/log"
+)
+
+type Platform struct {
+	renderer Renderer
+
+	inputChunks chan []byte // raw chunks of bytes read from stdin
+	inputOnce   sync.Once   // stdin only gets one reader, no matter how many times Init is called
+}
+
+func (p *Platform) Init() (err error) {
+	gfx.DefaultTextMode = gfx.TEXTMODE_HALF
+
+	p.renderer.out = os.Stdout
+	p.inputOnce.Do(func() {
+		p.inputChunks = make(chan []byte, 64)
+		go readInput(os.Stdin, p.inputChunks)
+	})
+
+	return
+}
+
+// Terminals don't really have titles, but most emulators support setting the window title with an OSC sequence so
+// we do that.
+func (p *Platform) ChangeTitle(title string) {
+	if !p.renderer.ready {
+		return
+	}
+
+	p.renderer.setTitle(title)
+}
+
+func (p *Platform) GetRenderer() tyumi.Renderer {
+	return &p.renderer
+}
+
+func (p *Platform) GetAudioSystem() tyumi.AudioSystem {
+	log.Error("Could not get audio system: the terminal platform does not support audio.")
+
+	return nil
+}
+
+func (p *Platform) Shutdown() {
+	p.renderer.Cleanup()
+}
+
+// Creates a platform for use by Tyumi. Pass this into tyumi.SetPlatform()
+func NewPlatform() *Platform {
+	terminal_platform := new(Platform)
+	return terminal_platform
+}
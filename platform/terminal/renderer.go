@@ -0,0 +1,239 @@
+package terminal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bennicholls/tyumi/gfx"
+	"github.com/bennicholls/tyumi/gfx/col"
+	"github.com/bennicholls/tyumi/log"
+	"github.com/bennicholls/tyumi/vec"
+	"golang.org/x/term"
+)
+
+// ANSI/VT100 control sequences used by the renderer.
+const (
+	csi = "\x1b["
+
+	seq_ALTSCREEN_ON  = csi + "?1049h"
+	seq_ALTSCREEN_OFF = csi + "?1049l"
+	seq_CURSOR_HIDE   = csi + "?25l"
+	seq_CURSOR_SHOW   = csi + "?25h"
+	seq_CLEAR         = csi + "2J"
+	seq_RESET         = csi + "0m"
+)
+
+type Renderer struct {
+	out    *os.File
+	buffer *bufio.Writer
+
+	oldState *term.State // terminal state before we switched to raw mode, so it can be restored on cleanup
+	termSize vec.Dims    // size of the terminal in cells (NOT terminal columns)
+
+	forceRedraw bool
+	showChanges bool
+
+	frames int // frames rendered. NOTE: this can differ from engine.tick since the renderer may not render every tick
+
+	clearColour col.Colour
+	debugColour col.Colour // for background when show changes is on
+
+	// colours most recently sent to the terminal, so we only send colour codes when they change
+	currentColours col.Pair
+	coloursSet     bool
+
+	console *gfx.Canvas
+
+	ready bool
+}
+
+func (r *Renderer) Setup(console *gfx.Canvas, glyphPath, fontPath, title string) (err error) {
+	if r.out == nil {
+		r.out = os.Stdout
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(r.out.Fd())) {
+		log.Error("TERMINAL RENDERER: stdin/stdout is not a terminal.")
+		return errors.New("not a terminal")
+	}
+
+	r.oldState, err = term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		log.Error("TERMINAL RENDERER: Could not put terminal into raw mode: ", err)
+		return
+	}
+
+	r.console = console
+	r.buffer = bufio.NewWriterSize(r.out, 1<<16)
+	r.clearColour = col.BLACK
+	r.debugColour = col.BLACK
+	r.updateTermSize()
+
+	r.buffer.WriteString(seq_ALTSCREEN_ON + seq_CURSOR_HIDE + seq_CLEAR)
+	r.ready = true
+	r.setTitle(title)
+	r.forceRedraw = true
+
+	return
+}
+
+func (r *Renderer) Ready() bool {
+	return r.ready
+}
+
+// Restores the terminal to the state it was in before the renderer was set up. Defer this function!
+func (r *Renderer) Cleanup() {
+	if !r.ready {
+		return
+	}
+
+	r.buffer.WriteString(seq_RESET + seq_CLEAR + seq_CURSOR_SHOW + seq_ALTSCREEN_OFF)
+	r.buffer.Flush()
+
+	if r.oldState != nil {
+		term.Restore(int(os.Stdin.Fd()), r.oldState)
+	}
+
+	r.ready = false
+	log.Info("Terminal Renderer shut down!")
+}
+
+// The terminal renders with whatever font the terminal emulator is using, so this does nothing.
+func (r *Renderer) ChangeFonts(glyphPath, fontPath string) (err error) {
+	return
+}
+
+// Fullscreen is up to the terminal emulator, so this does nothing.
+func (r *Renderer) SetFullscreen(enable bool) {}
+
+// Fullscreen is up to the terminal emulator, so this does nothing.
+func (r *Renderer) ToggleFullscreen() {}
+
+// Sets the colour used for cells that have nothing drawn in them.
+func (r *Renderer) SetClearColour(colour col.Colour) {
+	if r.clearColour == colour {
+		return
+	}
+
+	r.clearColour = colour
+	r.forceRedraw = true
+}
+
+func (r *Renderer) ForceRedraw() {
+	r.forceRedraw = true
+}
+
+func (r *Renderer) ToggleDebugMode(m string) {
+	switch m {
+	case "changes":
+		r.showChanges = !r.showChanges
+		log.Debug("TERMINAL RENDERER: Enabled cell change display debug mode.")
+	default:
+		log.Error("TERMINAL RENDERER: no debug mode called ", m)
+	}
+}
+
+func (r *Renderer) setTitle(title string) {
+	r.buffer.WriteString("\x1b]0;" + title + "\x07")
+	r.buffer.Flush()
+}
+
+// checks the terminal size, triggering a full redraw if it changed.
+func (r *Renderer) updateTermSize() {
+	w, h, err := term.GetSize(int(r.out.Fd()))
+	if err != nil {
+		return
+	}
+
+	if size := (vec.Dims{w / 2, h}); size != r.termSize {
+		r.termSize = size
+		r.forceRedraw = true
+	}
+}
+
+// Renders the console to the terminal. Only cells that have changed since the last render are sent, unless a full
+// redraw has been requested. Cells that don't fit in the terminal are skipped.
+func (r *Renderer) Render() {
+	if !r.ready {
+		return
+	}
+
+	if r.showChanges {
+		r.debugColour = col.MakeOpaque(uint8((r.frames*10)%255), uint8(((r.frames+100)*10)%255), uint8(((r.frames+200)*10)%255))
+	}
+
+	if r.forceRedraw {
+		r.setColours(col.Pair{r.clearColour, r.clearColour})
+		r.buffer.WriteString(seq_CLEAR)
+	}
+
+	if r.forceRedraw || r.console.Dirty() {
+		draw_area := vec.FindIntersectionRect(r.console, r.termSize)
+		lastDrawn := vec.Coord{-1, -1}
+		for cursor := range vec.EachCoordInArea(draw_area) {
+			if !r.forceRedraw && !r.console.IsDirtyAt(cursor) {
+				continue
+			}
+
+			// only move the terminal's cursor if we're not drawing directly after the last cell
+			if lastDrawn.Y != cursor.Y || lastDrawn.X != cursor.X-1 {
+				fmt.Fprintf(r.buffer, csi+"%d;%dH", cursor.Y+1, cursor.X*2+1)
+			}
+
+			r.drawCell(r.console.GetCell(cursor))
+			lastDrawn = cursor
+		}
+	}
+
+	r.buffer.Flush()
+	r.console.Clean()
+	r.forceRedraw = false
+	r.frames++
+}
+
+// writes a cell to the terminal at the current cursor position. each cell takes up 2 terminal columns.
+func (r *Renderer) drawCell(cell gfx.Visuals) {
+	colours := cell.Colours
+	if colours.Back == col.NONE {
+		colours.Back = r.clearColour
+	}
+
+	if r.showChanges {
+		colours.Back = r.debugColour
+	}
+
+	var chars [2]rune
+	switch cell.Mode {
+	case gfx.DRAW_GLYPH:
+		chars[0] = glyphToRune(cell.Glyph)
+		chars[1] = glyphFiller(chars[0])
+	case gfx.DRAW_TEXT:
+		chars[0] = textToRune(cell.Chars[0])
+		chars[1] = textToRune(cell.Chars[1])
+	default:
+		chars = [2]rune{' ', ' '}
+	}
+
+	if colours.Fore == col.NONE {
+		colours.Fore = colours.Back
+	}
+
+	r.setColours(colours)
+	r.buffer.WriteRune(chars[0])
+	r.buffer.WriteRune(chars[1])
+}
+
+func (r *Renderer) setColours(colours col.Pair) {
+	if r.coloursSet && r.currentColours == colours {
+		return
+	}
+
+	fr, fg, fb := colours.Fore.RGB()
+	br, bg, bb := colours.Back.RGB()
+	fmt.Fprintf(r.buffer, csi+"38;2;%d;%d;%d;48;2;%d;%d;%dm", fr, fg, fb, br, bg, bb)
+
+	r.currentColours = colours
+	r.coloursSet = true
+}
@@ -0,0 +1,124 @@
+package tyumi
+
+import (
+	"errors"
+
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/input"
+	"github.com/bennicholls/tyumi/log"
+)
+
+// Input recording and replay. While recording, every input event fired by the platform is saved along with the tick
+// it happened on. Replaying a recording fires those same events on the same ticks (relative to the start of the
+// replay) instead of asking the platform for new ones. Along with a seeded RNG and a fixed frame delta (see
+// SetFixedFrameDelta()) this lets you reproduce a play session exactly.
+var (
+	recorder           event.Stream
+	recording          input.Recording
+	recordingStartTick int
+	isRecording        bool
+	generatingEvents   bool // true while the platform is generating events, so we only record platform input
+
+	replay          input.Recording
+	replayIndex     int
+	replayStartTick int
+	isReplaying     bool
+)
+
+// StartRecording begins recording input events fired by the platform. Any previous unsaved recording is discarded.
+func StartRecording() {
+	if isReplaying {
+		log.Error("Cannot record input while replaying input.")
+		return
+	}
+
+	recording = input.Recording{}
+	recordingStartTick = tick
+	isRecording = true
+
+	recorder.SetImmediateEventHandler(recordEvent)
+	recorder.Listen(input.EV_KEYBOARD, input.EV_MOUSEMOVE, input.EV_MOUSEBUTTON, input.EV_MOUSEWHEEL, input.EV_ACTION)
+	recorder.EnableListening()
+}
+
+// StopRecording stops recording input and saves the recording to path.
+func StopRecording(path string) (err error) {
+	if !isRecording {
+		return errors.New("not recording")
+	}
+
+	isRecording = false
+	recorder.DisableListening()
+
+	return recording.Save(path)
+}
+
+func IsRecording() bool {
+	return isRecording
+}
+
+func recordEvent(e event.Event) (event_handled bool) {
+	if generatingEvents {
+		recording.Add(tick-recordingStartTick, e)
+	}
+
+	return
+}
+
+// StartReplay loads a recording from path and begins replaying it on the next tick. While replaying, the platform is
+// not polled for events. Once all recorded events have been fired the replay ends and normal input resumes.
+func StartReplay(path string) (err error) {
+	if isRecording {
+		log.Error("Cannot replay input while recording input.")
+		return errors.New("currently recording")
+	}
+
+	replay, err = input.LoadRecording(path)
+	if err != nil {
+		return
+	}
+
+	replayIndex = 0
+	replayStartTick = tick
+	isReplaying = true
+
+	return
+}
+
+// StopReplay ends a replay early and resumes normal input.
+func StopReplay() {
+	isReplaying = false
+	replay = input.Recording{}
+}
+
+func IsReplaying() bool {
+	return isReplaying
+}
+
+// collects input events for the frame, either from the platform or from a replay.
+func generateEvents() {
+	if isReplaying {
+		replayEvents()
+		return
+	}
+
+	generatingEvents = true
+	currentPlatform.GenerateEvents()
+	generatingEvents = false
+}
+
+// fires all recorded events for the current tick.
+func replayEvents() {
+	replayTick := tick - replayStartTick
+	for ; replayIndex < replay.Count(); replayIndex++ {
+		record := replay.At(replayIndex)
+		if record.Tick > replayTick {
+			return
+		}
+
+		record.Fire()
+	}
+
+	log.Info("Input replay complete.")
+	StopReplay()
+}
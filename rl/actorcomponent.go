@@ -0,0 +1,215 @@
+package rl
+
+import (
+	"time"
+
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/rl/ecs"
+)
+
+func init() {
+	ecs.Register[ActorComponent]("rl.Actor")
+}
+
+var EV_PLAYERTURN = event.Register("It is the player's turn to act.")
+
+const (
+	ACTION_COST  int = 100 // energy cost of a standard action. actors can act once they have at least this much energy.
+	SPEED_NORMAL int = 10  // energy gained per tick by an actor of normal speed
+)
+
+// ActorComponent is for entities that take turns. Each tick of game time, actors gain energy equal to their speed;
+// once an actor has enough energy (ACTION_COST) it takes a turn, and the action it performs spends some energy. Faster
+// actors gain energy more quickly and so act more often, and cheap actions let an actor act again sooner.
+type ActorComponent struct {
+	ecs.Component
+
+	Speed  int // energy gained per tick. defaults to SPEED_NORMAL.
+	Energy int
+}
+
+func (ac *ActorComponent) Init() {
+	if ac.Speed == 0 {
+		ac.Speed = SPEED_NORMAL
+	}
+}
+
+// Ready reports whether the actor has enough energy to act.
+func (ac ActorComponent) Ready() bool {
+	return ac.Energy >= ACTION_COST
+}
+
+// ticks until the actor is ready to act. returns -1 if the actor will never be ready.
+func (ac ActorComponent) ticksUntilReady() int {
+	if ac.Ready() {
+		return 0
+	} else if ac.Speed <= 0 {
+		return -1
+	}
+
+	return (ACTION_COST - ac.Energy + ac.Speed - 1) / ac.Speed
+}
+
+// TurnSystem schedules turns for the actors in a tilemap. Game time only moves forward when no actor is ready, so
+// the game advances in discrete turns instead of following wall-clock time.
+//
+// When it is the player's turn the system waits for input: EV_PLAYERTURN is fired, AwaitingInput() reports true, and
+// nothing else happens until the player's action is reported with EndTurn(). Turns are also paused while the tilemap
+// has a blocking animation playing, so animations for one action finish before the next actor moves.
+//
+// Other actors are run by the ActHandler function. Without one, the system just reports whose turn it is and you can
+// drive it manually with Next() and EndTurn().
+type TurnSystem struct {
+	System
+
+	// ActHandler is called when it is a non-player actor's turn. It should perform the actor's action and return
+	// its energy cost. Returning 0 means the actor is not done yet (waiting on something, perhaps); the handler will
+	// be called again for the same actor next update.
+	ActHandler func(actor Entity) (cost int)
+
+	Tick int // ticks of game time elapsed
+
+	tileMap *TileMap
+	current Entity
+}
+
+func (ts *TurnSystem) Init(tm *TileMap) {
+	ts.tileMap = tm
+	ts.current = INVALID_ENTITY
+}
+
+// Update runs turns for actors until the player needs to act or an actor's action starts a blocking animation. FOV is
+// brought up to date before each actor acts. If there is no player in the tilemap, game time is only moved forward
+// once per update so the game doesn't lock up.
+func (ts *TurnSystem) Update(delta time.Duration) {
+	advanced := false
+	hasPlayer := ts.hasPlayer()
+	for !ts.tileMap.checkBlocking() {
+		if !ts.current.IsValid() || !ts.isActor(ts.current) {
+			if advanced && !hasPlayer && !ts.anyReady() {
+				return
+			}
+
+			advanced = true
+			if ts.Next() == INVALID_ENTITY {
+				return
+			}
+		}
+
+		if ts.current.IsPlayer() || ts.ActHandler == nil {
+			return
+		}
+
+		// earlier actors may have moved or changed the map, so make sure this actor sees what is there now
+		ts.tileMap.FOVSystem.Update(delta)
+
+		cost := ts.ActHandler(ts.current)
+		if cost <= 0 {
+			return
+		}
+
+		ts.EndTurn(cost)
+	}
+}
+
+// Next returns the actor whose turn it is, moving game time forward until someone is ready if necessary. If the
+// current actor has not finished its turn, it is returned again. Returns INVALID_ENTITY if there are no actors that
+// can act.
+func (ts *TurnSystem) Next() Entity {
+	if ts.current.IsValid() && ts.isActor(ts.current) {
+		return ts.current
+	}
+
+	ts.current = INVALID_ENTITY
+	if !ts.anyReady() {
+		ticks := -1
+		for actor, entity := range ecs.EachComponent[ActorComponent]() {
+			if until := actor.ticksUntilReady(); until != -1 && ts.inMap(Entity(entity)) && (ticks == -1 || until < ticks) {
+				ticks = until
+			}
+		}
+
+		if ticks == -1 {
+			return INVALID_ENTITY
+		}
+
+		for actor, entity := range ecs.EachComponent[ActorComponent]() {
+			if ts.inMap(Entity(entity)) {
+				actor.Energy += ticks * actor.Speed
+			}
+		}
+		ts.Tick += ticks
+	}
+
+	// the actor with the most energy goes first
+	var best *ActorComponent
+	for actor, entity := range ecs.EachComponent[ActorComponent]() {
+		if actor.Ready() && ts.inMap(Entity(entity)) && (best == nil || actor.Energy > best.Energy) {
+			best = actor
+			ts.current = Entity(entity)
+		}
+	}
+
+	if ts.current.IsPlayer() {
+		event.Fire(EV_PLAYERTURN, &EntityEvent{Entity: ts.current})
+	}
+
+	return ts.current
+}
+
+// Current returns the actor whose turn it is, or INVALID_ENTITY if no turn is in progress.
+func (ts *TurnSystem) Current() Entity {
+	return ts.current
+}
+
+// AwaitingInput reports whether the system is waiting for the player to act.
+func (ts *TurnSystem) AwaitingInput() bool {
+	return ts.current.IsValid() && ts.isActor(ts.current) && ts.current.IsPlayer()
+}
+
+// EndTurn ends the current actor's turn, spending the provided amount of energy. Fires EV_TURNENDED so anything that
+// happens at the end of a turn (regeneration, for example) can be applied.
+func (ts *TurnSystem) EndTurn(cost int) {
+	if !ts.current.IsValid() {
+		return
+	}
+
+	if actor := ecs.Get[ActorComponent](ts.current); actor != nil {
+		actor.Energy -= cost
+	}
+
+	ended := ts.current
+	ts.current = INVALID_ENTITY
+	event.Fire(EV_TURNENDED, &EntityEvent{Entity: ended})
+}
+
+func (ts *TurnSystem) isActor(entity Entity) bool {
+	return ecs.Alive(entity) && ecs.Has[ActorComponent](entity) && ts.inMap(entity)
+}
+
+// only actors in the tilemap take turns
+func (ts *TurnSystem) inMap(entity Entity) bool {
+	position := ecs.Get[PositionComponent](entity)
+	return position != nil && ts.tileMap.Bounds().Contains(position.Coord) &&
+		ts.tileMap.GetTile(position.Coord).GetEntity() == entity
+}
+
+func (ts *TurnSystem) anyReady() bool {
+	for actor, entity := range ecs.EachComponent[ActorComponent]() {
+		if actor.Ready() && ts.inMap(Entity(entity)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ts *TurnSystem) hasPlayer() bool {
+	for _, entity := range ecs.EachComponent[PlayerComponent]() {
+		if ecs.Has[ActorComponent](entity) && ts.inMap(Entity(entity)) {
+			return true
+		}
+	}
+
+	return false
+}
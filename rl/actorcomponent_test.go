@@ -0,0 +1,210 @@
+package rl
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bennicholls/tyumi/anim"
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/rl/ecs"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+var turnTestActor = RegisterEntityType(EntityData{Name: "turn_test_actor"})
+
+// makes a tilemap with an actor for each speed, lined up along the top row.
+func newTurnTestMap(t *testing.T, speeds ...int) (tm *TileMap, actors []Entity) {
+	t.Helper()
+
+	tm = new(TileMap)
+	tm.Init(vec.Dims{10, 3}, saveTestFloor)
+	for i, speed := range speeds {
+		actor := CreateEntity(turnTestActor)
+		ecs.Add(actor, ActorComponent{Speed: speed})
+		tm.AddEntity(actor, vec.Coord{i, 0})
+		actors = append(actors, actor)
+	}
+
+	t.Cleanup(func() {
+		for _, actor := range actors {
+			ecs.DestroyEntity(actor)
+		}
+		tm.Cleanup()
+	})
+
+	return
+}
+
+func TestTicksUntilReady(t *testing.T) {
+	tests := []struct {
+		energy, speed int
+		ticks         int
+	}{
+		{0, 10, 10},
+		{95, 10, 1},
+		{91, 10, 1},
+		{90, 10, 1},
+		{89, 10, 2},
+		{0, 30, 4}, // 3.33 ticks, rounded up
+		{100, 10, 0},
+		{150, 0, 0},
+		{0, 0, -1},
+		{-50, 25, 6},
+	}
+
+	for _, test := range tests {
+		actor := ActorComponent{Energy: test.energy, Speed: test.speed}
+		if ticks := actor.ticksUntilReady(); ticks != test.ticks {
+			t.Errorf("Energy %d, speed %d: %d ticks until ready, wanted %d", test.energy, test.speed, ticks, test.ticks)
+		}
+	}
+}
+
+func TestTurnOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		speeds []int
+		order  []int // indices into speeds of the first few actors to take a turn
+		ticks  int   // game time elapsed after those turns
+	}{
+		{"single actor", []int{10}, []int{0, 0, 0}, 30},
+		{"faster actor", []int{10, 25}, []int{1, 1, 0, 1, 1}, 16},
+		{"triple speed", []int{30, 10}, []int{0, 0, 0, 1, 0, 0, 0, 1}, 20},
+		{"overshooting energy carries over", []int{20, 15}, []int{0, 1, 0}, 10},
+		{"most energy goes first", []int{10, 22}, []int{1, 1, 0}, 10},
+	}
+
+	for _, test := range tests {
+		tm, actors := newTurnTestMap(t, test.speeds...)
+
+		var order []int
+		for range test.order {
+			order = append(order, slices.Index(actors, tm.TurnSystem.Next()))
+			tm.TurnSystem.EndTurn(ACTION_COST)
+		}
+
+		if !slices.Equal(order, test.order) || tm.TurnSystem.Tick != test.ticks {
+			t.Errorf("%s: turn order %v after %d ticks, wanted %v after %d", test.name, order, tm.TurnSystem.Tick, test.order, test.ticks)
+		}
+	}
+}
+
+func TestTurnSystemPauses(t *testing.T) {
+	tests := []struct {
+		name      string
+		speeds    []int // the last actor is the npc
+		setup     func(tm *TileMap, actors []Entity)
+		cost      func(actor Entity) int // cost of the npc's action
+		acted     int                    // npc turns taken during the first update
+		paused    func(tm *TileMap) bool
+		unpause   func(tm *TileMap)
+		actedNext int // npc turns taken by the update after unpausing
+	}{
+		{
+			name:      "player turn",
+			speeds:    []int{10, 22},
+			setup:     func(tm *TileMap, actors []Entity) { ecs.Add(actors[0], PlayerComponent{}) },
+			cost:      func(actor Entity) int { return ACTION_COST },
+			acted:     2, // npc is faster, so gets 2 turns before the player
+			paused:    func(tm *TileMap) bool { return tm.TurnSystem.AwaitingInput() },
+			unpause:   func(tm *TileMap) { tm.TurnSystem.EndTurn(ACTION_COST) },
+			actedNext: 2,
+		},
+		{
+			name:   "blocking animation",
+			speeds: []int{10},
+			setup:  func(tm *TileMap, actors []Entity) {},
+			cost: func(actor Entity) int {
+				AddAnimation(actor, &anim.Animation{Blocking: true, Duration: time.Second}, true)
+				return ACTION_COST
+			},
+			acted:  1,
+			paused: func(tm *TileMap) bool { return tm.checkBlocking() },
+			unpause: func(tm *TileMap) {
+				// first update starts the animation, second finishes it
+				tm.AnimationSystem.Update(time.Second)
+				tm.AnimationSystem.Update(time.Second)
+			},
+			actedNext: 1,
+		},
+	}
+
+	for _, test := range tests {
+		tm, actors := newTurnTestMap(t, test.speeds...)
+		test.setup(tm, actors)
+
+		playerTurns := 0
+		listener := event.NewStream(10, nil)
+		listener.SetImmediateEventHandler(func(e event.Event) bool {
+			playerTurns++
+			return true
+		})
+		listener.Listen(EV_PLAYERTURN)
+
+		acted := 0
+		tm.TurnSystem.ActHandler = func(actor Entity) int {
+			if actor != actors[len(actors)-1] {
+				t.Errorf("%s: ActHandler called for %v", test.name, actor)
+			}
+			acted++
+			return test.cost(actor)
+		}
+
+		tm.TurnSystem.Update(0)
+		if acted != test.acted || !test.paused(tm) {
+			t.Errorf("%s: %d turns taken before pausing, wanted %d", test.name, acted, test.acted)
+		}
+
+		tm.TurnSystem.Update(0)
+		if acted != test.acted {
+			t.Errorf("%s: turns were taken while paused", test.name)
+		}
+
+		acted = 0
+		test.unpause(tm)
+		tm.TurnSystem.Update(0)
+		if acted != test.actedNext {
+			t.Errorf("%s: %d turns taken after unpausing, wanted %d", test.name, acted, test.actedNext)
+		}
+
+		if test.name == "player turn" && playerTurns != 2 {
+			t.Errorf("%s: EV_PLAYERTURN fired %d times, wanted 2", test.name, playerTurns)
+		}
+
+		listener.DisableListening()
+	}
+}
+
+// an actor that is moved by someone else's action should see from its new position when its own turn comes around.
+func TestTurnSystemFOVUpToDate(t *testing.T) {
+	tm, actors := newTurnTestMap(t, SPEED_NORMAL*2, SPEED_NORMAL)
+	mover, viewer := actors[0], actors[1]
+	ecs.Add(viewer, FOVComponent{SightRange: 2})
+	tm.FOVSystem.Update(0)
+
+	moved := false
+	tm.TurnSystem.ActHandler = func(actor Entity) int {
+		switch actor {
+		case mover:
+			if !moved {
+				tm.MoveEntity(viewer, vec.Coord{7, 2})
+				moved = true
+			}
+		case viewer:
+			if fov := ecs.Get[FOVComponent](viewer); fov.Dirty || !fov.InFOV(vec.Coord{8, 2}) || fov.InFOV(vec.Coord{1, 0}) {
+				t.Error("Actor acted with a stale FOV.")
+			}
+		}
+
+		return ACTION_COST
+	}
+
+	for range 3 {
+		tm.TurnSystem.Update(0)
+	}
+
+	if !moved {
+		t.Error("Mover never took a turn.")
+	}
+}
@@ -0,0 +1,348 @@
+package rl
+
+import (
+	"math/rand/v2"
+
+	"github.com/bennicholls/tyumi/log"
+	"github.com/bennicholls/tyumi/rl/ecs"
+	"github.com/bennicholls/tyumi/util"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// AI behaviours are built out of nodes. Each node does some work when run (checking a condition, moving the entity,
+// etc.) and reports whether it succeeded, failed, or is still in progress. Nodes can be combined into behaviour trees
+// using AISequence and AISelector, or chosen between using utility scores with AIUtility.
+//
+// Nodes are registered by name with RegisterAINode() so AIComponents can refer to them (and be saved). Tyumi's stock
+// nodes are registered with names prefixed with "rl.". Register your own nodes to make custom behaviours, or register
+// over the stock names to replace them.
+//
+// The stock movement nodes step greedily toward or away from things, which is fine in open areas but easily foiled by
+// walls. For smarter movement, write a node using the rl/path package.
+
+func init() {
+	RegisterAINode("rl.Wait", AIWait)
+	RegisterAINode("rl.Wander", AIWander)
+	RegisterAINode("rl.Hunt", AIHunt)
+	RegisterAINode("rl.Search", AISearch)
+	RegisterAINode("rl.Flee", AIFlee)
+	RegisterAINode("rl.CanSeeTarget", AICanSeeTarget)
+	RegisterAINode("rl.HasLastSeen", AIHasLastSeen)
+	RegisterAINode("rl.IsHurt", AIIsHurt)
+	RegisterAINode("rl.Monster", AIMonster)
+}
+
+type AIStatus uint8
+
+const (
+	AI_SUCCESS AIStatus = iota
+	AI_FAILURE
+	AI_RUNNING // the node is still working on something and will continue on later turns
+)
+
+// AINode is a piece of AI behaviour.
+type AINode interface {
+	Run(ctx *AIContext) AIStatus
+}
+
+var aiNodes map[string]AINode = make(map[string]AINode)
+
+// RegisterAINode registers an AI node under the provided name. AIComponents refer to their behaviour by name, so it
+// should stay the same between versions of your game if you want old saves to work. Registering a node with a name
+// that is already in use replaces the old node.
+func RegisterAINode(name string, node AINode) {
+	if node == nil {
+		log.Error("AI: cannot register nil node ", name)
+		return
+	}
+
+	aiNodes[name] = node
+}
+
+// GetAINode returns the AI node registered with the provided name, or nil if there isn't one.
+func GetAINode(name string) AINode {
+	return aiNodes[name]
+}
+
+// AIContext is passed to AI nodes when they run.
+type AIContext struct {
+	TileMap *TileMap
+	Entity  Entity
+	AI      *AIComponent
+	Cost    int        // energy cost of the action taken. nodes that perform an action should set this.
+	RNG     *rand.Rand // source of randomness for the AI's decisions. if nil, the global source is used.
+}
+
+// Target returns the AI's target, or INVALID_ENTITY if it doesn't have one.
+func (ctx *AIContext) Target() Entity {
+	if !ctx.AI.HasTarget() {
+		return INVALID_ENTITY
+	}
+
+	return ctx.AI.Target
+}
+
+// returns a random permutation of [0, n) using the context's RNG.
+func (ctx *AIContext) perm(n int) []int {
+	if ctx.RNG == nil {
+		return rand.Perm(n)
+	}
+
+	return ctx.RNG.Perm(n)
+}
+
+// StepToward moves the entity one step closer to the goal. Returns false if no step gets it any closer.
+func (ctx *AIContext) StepToward(goal vec.Coord) bool {
+	return ctx.step(func(pos vec.Coord) int { return pos.DistanceSqTo(goal) })
+}
+
+// StepAway moves the entity one step further away from the threat. Returns false if no step gets it any further.
+func (ctx *AIContext) StepAway(threat vec.Coord) bool {
+	return ctx.step(func(pos vec.Coord) int { return -pos.DistanceSqTo(threat) })
+}
+
+// moves the entity to the passable neighbouring tile with the lowest score, if that is lower than the score of where
+// the entity is now.
+func (ctx *AIContext) step(score func(pos vec.Coord) int) bool {
+	from := ctx.Entity.Position()
+	best, bestScore := from, score(from)
+	for _, dir := range vec.Directions {
+		to := from.Step(dir)
+		if !to.IsInside(ctx.TileMap) || !ctx.TileMap.GetTile(to).IsPassable() {
+			continue
+		}
+
+		if s := score(to); s < bestScore {
+			best, bestScore = to, s
+		}
+	}
+
+	if best == from || !ctx.TileMap.MoveEntity(ctx.Entity, best) {
+		return false
+	}
+
+	ctx.Cost = ACTION_COST
+	return true
+}
+
+// AIAction is a node that runs a function.
+type AIAction func(ctx *AIContext) AIStatus
+
+func (a AIAction) Run(ctx *AIContext) AIStatus {
+	return a(ctx)
+}
+
+// AICondition is a node that succeeds if the function returns true, and fails otherwise.
+type AICondition func(ctx *AIContext) bool
+
+func (c AICondition) Run(ctx *AIContext) AIStatus {
+	if c(ctx) {
+		return AI_SUCCESS
+	}
+
+	return AI_FAILURE
+}
+
+// AISequence runs its nodes in order until one of them doesn't succeed, and reports that node's status. Succeeds if
+// all of the nodes succeed.
+type AISequence []AINode
+
+func (s AISequence) Run(ctx *AIContext) AIStatus {
+	for _, node := range s {
+		if status := node.Run(ctx); status != AI_SUCCESS {
+			return status
+		}
+	}
+
+	return AI_SUCCESS
+}
+
+// AISelector runs its nodes in order until one of them doesn't fail, and reports that node's status. Fails if all of
+// the nodes fail.
+type AISelector []AINode
+
+func (s AISelector) Run(ctx *AIContext) AIStatus {
+	for _, node := range s {
+		if status := node.Run(ctx); status != AI_FAILURE {
+			return status
+		}
+	}
+
+	return AI_FAILURE
+}
+
+// AIInverter runs its node and flips the result: success becomes failure, and failure becomes success.
+type AIInverter struct {
+	Node AINode
+}
+
+func (i AIInverter) Run(ctx *AIContext) AIStatus {
+	switch status := i.Node.Run(ctx); status {
+	case AI_SUCCESS:
+		return AI_FAILURE
+	case AI_FAILURE:
+		return AI_SUCCESS
+	default:
+		return status
+	}
+}
+
+// AIOption is a choice for an AIUtility node. Score should return how desirable the option is right now.
+type AIOption struct {
+	Score func(ctx *AIContext) float64
+	Node  AINode
+}
+
+// AIUtility scores each of its options and runs the one with the highest score. Options scoring 0 or less are never
+// chosen. If the chosen node fails, the next best option is tried, and so on. Fails if no options succeed.
+type AIUtility []AIOption
+
+func (u AIUtility) Run(ctx *AIContext) AIStatus {
+	scores := make([]float64, len(u))
+	for i, option := range u {
+		scores[i] = option.Score(ctx)
+	}
+
+	for range u {
+		best := -1
+		for i, score := range scores {
+			if score > 0 && (best == -1 || score > scores[best]) {
+				best = i
+			}
+		}
+
+		if best == -1 {
+			break
+		}
+
+		if status := u[best].Node.Run(ctx); status != AI_FAILURE {
+			return status
+		}
+
+		scores[best] = 0
+	}
+
+	return AI_FAILURE
+}
+
+// AINodeRef is a node that runs the node registered with the name. Use this to refer to nodes that might be registered
+// (or replaced) after your behaviour is built.
+type AINodeRef string
+
+func (r AINodeRef) Run(ctx *AIContext) AIStatus {
+	node := GetAINode(string(r))
+	if node == nil {
+		log.Error("AI: no node registered with name ", string(r))
+		return AI_FAILURE
+	}
+
+	return node.Run(ctx)
+}
+
+// Stock nodes.
+var (
+	// AIWait does nothing for a turn.
+	AIWait AINode = AIAction(func(ctx *AIContext) AIStatus {
+		ctx.AI.State = AI_IDLE
+		ctx.Cost = ACTION_COST
+		return AI_SUCCESS
+	})
+
+	// AIWander moves in a random direction. Fails if the entity is boxed in.
+	AIWander AINode = AIAction(func(ctx *AIContext) AIStatus {
+		pos := ctx.Entity.Position()
+		for _, i := range ctx.perm(len(vec.Directions)) {
+			to := pos.Step(vec.Directions[i])
+			if to.IsInside(ctx.TileMap) && ctx.TileMap.GetTile(to).IsPassable() && ctx.TileMap.MoveEntity(ctx.Entity, to) {
+				ctx.AI.State = AI_WANDER
+				ctx.Cost = ACTION_COST
+				return AI_SUCCESS
+			}
+		}
+
+		return AI_FAILURE
+	})
+
+	// AIHunt moves toward the AI's target. Once next to the target it succeeds without doing anything, so put your
+	// attack node after it in a sequence. Fails if there is no target, or no way to get closer to it.
+	AIHunt AINode = AIAction(func(ctx *AIContext) AIStatus {
+		target := ctx.Target()
+		if !target.IsValid() || !target.IsInTilemap() {
+			return AI_FAILURE
+		}
+
+		ctx.AI.State = AI_HUNT
+		pos, targetPos := ctx.Entity.Position(), target.Position()
+		if max(util.Abs(pos.X-targetPos.X), util.Abs(pos.Y-targetPos.Y)) <= 1 {
+			return AI_SUCCESS
+		}
+
+		if ctx.StepToward(targetPos) {
+			return AI_RUNNING
+		}
+
+		return AI_FAILURE
+	})
+
+	// AISearch heads to where the target was last seen. Succeeds once it gets there, at which point the AI gives up
+	// and forgets where it was. Fails if there is nowhere to search, or no way to get closer.
+	AISearch AINode = AIAction(func(ctx *AIContext) AIStatus {
+		if ctx.AI.LastSeen == NOT_IN_TILEMAP {
+			return AI_FAILURE
+		}
+
+		ctx.AI.State = AI_SEARCH
+		if ctx.Entity.Position() != ctx.AI.LastSeen && !ctx.StepToward(ctx.AI.LastSeen) {
+			ctx.AI.LastSeen = NOT_IN_TILEMAP
+			return AI_FAILURE
+		}
+
+		if ctx.Entity.Position() == ctx.AI.LastSeen {
+			ctx.AI.LastSeen = NOT_IN_TILEMAP
+			return AI_SUCCESS
+		}
+
+		return AI_RUNNING
+	})
+
+	// AIFlee moves away from the AI's target. Fails if there is no target, or nowhere further to run.
+	AIFlee AINode = AIAction(func(ctx *AIContext) AIStatus {
+		target := ctx.Target()
+		if !target.IsValid() || !target.IsInTilemap() {
+			return AI_FAILURE
+		}
+
+		ctx.AI.State = AI_FLEE
+		if ctx.StepAway(target.Position()) {
+			return AI_RUNNING
+		}
+
+		return AI_FAILURE
+	})
+
+	// AICanSeeTarget succeeds if the AI has a target and it is in view.
+	AICanSeeTarget AINode = AICondition(func(ctx *AIContext) bool {
+		return ctx.AI.HasTarget() && ctx.AI.TargetVisible
+	})
+
+	// AIHasLastSeen succeeds if the AI remembers somewhere it last saw its target.
+	AIHasLastSeen AINode = AICondition(func(ctx *AIContext) bool {
+		return ctx.AI.LastSeen != NOT_IN_TILEMAP
+	})
+
+	// AIIsHurt succeeds if the entity's health is at a quarter or less of its maximum.
+	AIIsHurt AINode = AICondition(func(ctx *AIContext) bool {
+		health := ecs.Get[HealthComponent](ctx.Entity)
+		return health != nil && health.HP.Get()*4 <= health.HP.Max()
+	})
+
+	// AIMonster is a basic monster: it flees when badly hurt, hunts anything hostile it can see, searches for targets
+	// it has lost track of (unless it's hurt), and otherwise wanders around.
+	AIMonster AINode = AISelector{
+		AISequence{AINodeRef("rl.IsHurt"), AINodeRef("rl.CanSeeTarget"), AINodeRef("rl.Flee")},
+		AISequence{AINodeRef("rl.CanSeeTarget"), AINodeRef("rl.Hunt")},
+		AISequence{AIInverter{AINodeRef("rl.IsHurt")}, AINodeRef("rl.Search")},
+		AINodeRef("rl.Wander"),
+		AINodeRef("rl.Wait"),
+	}
+)
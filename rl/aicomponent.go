@@ -0,0 +1,175 @@
+package rl
+
+import (
+	"math/rand/v2"
+
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/log"
+	"github.com/bennicholls/tyumi/rl/ecs"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+func init() {
+	ecs.Register[AIComponent]("rl.AI")
+}
+
+// AIState describes what an AI is currently up to. States are set by behaviour nodes as they run, so games can display
+// them or react to them however they like.
+type AIState uint8
+
+const (
+	AI_IDLE   AIState = iota
+	AI_WANDER         // moving around aimlessly
+	AI_HUNT           // chasing a target it can see
+	AI_SEARCH         // heading to where it last saw its target
+	AI_FLEE           // running away from its target
+)
+
+// AIComponent is for entities controlled by the AI system. Each turn the behaviour named by Behaviour is run to decide
+// what the entity does (see RegisterAINode()).
+//
+// AIs find targets using their FOVComponent: when they gain sight of a hostile entity it becomes their target, and
+// when they lose sight of it they remember where it was last seen. For this to work the entity needs an FOVComponent
+// with TrackEntities set to true.
+type AIComponent struct {
+	ecs.Component
+
+	Behaviour     string    // name of the registered behaviour node to run. defaults to "rl.Monster".
+	State         AIState   // what the AI is currently doing
+	Target        Entity    // the entity the AI is interested in
+	TargetVisible bool      // whether the target is currently in view
+	LastSeen      vec.Coord // where the target was last seen. NOT_IN_TILEMAP if the AI has nowhere to search.
+}
+
+func (ai *AIComponent) Init() {
+	if ai.Behaviour == "" {
+		ai.Behaviour = "rl.Monster"
+	}
+
+	// AIs with no target have nowhere to search
+	if !ai.Target.IsValid() && ai.LastSeen == (vec.Coord{}) {
+		ai.LastSeen = NOT_IN_TILEMAP
+	}
+}
+
+func (ai *AIComponent) RemapEntities(remap ecs.EntityRemap) {
+	if ai.Target.IsValid() {
+		ai.Target = Entity(remap.Remap(ecs.Entity(ai.Target)))
+	}
+}
+
+// HasTarget reports whether the AI has a target that still exists.
+func (ai AIComponent) HasTarget() bool {
+	return ai.Target.IsValid() && ecs.Alive(ai.Target)
+}
+
+// ClearTarget makes the AI forget about its target, including where it last saw it.
+func (ai *AIComponent) ClearTarget() {
+	ai.Target = INVALID_ENTITY
+	ai.TargetVisible = false
+	ai.LastSeen = NOT_IN_TILEMAP
+}
+
+// AISystem runs AI behaviours for entities with an AIComponent. Plug it into the turn system to have AI entities act
+// on their turns:
+//
+//	tilemap.ActHandler = tilemap.RunAI
+type AISystem struct {
+	System
+
+	// IsHostile reports whether an AI entity should target another entity it has seen. If nil, AIs target the player.
+	IsHostile func(entity, other Entity) bool
+
+	// RNG is the source of randomness for AI decisions (wandering, etc.). If nil, the global source is used. Set this
+	// to make AI behaviour reproducible, for tests or replays.
+	RNG *rand.Rand
+
+	tileMap *TileMap
+}
+
+func (ais *AISystem) Init(tm *TileMap) {
+	ais.tileMap = tm
+	ais.Listen(EV_GAINEDSIGHT, EV_LOSTSIGHT, EV_ENTITYBEINGDESTROYED, EV_ENTITYDIED)
+	ais.SetImmediateEventHandler(ais.immediateHandleEvent)
+}
+
+func (ais *AISystem) immediateHandleEvent(e event.Event) (event_handled bool) {
+	switch e.ID() {
+	case EV_GAINEDSIGHT:
+		sight := e.(*EntitySightEvent)
+		ai := ecs.Get[AIComponent](sight.Viewer)
+		if ai == nil {
+			return
+		}
+
+		if sight.TrackedEntity == ai.Target {
+			ai.TargetVisible = true
+		} else if !(ai.HasTarget() && ai.TargetVisible) && ais.isHostile(sight.Viewer, sight.TrackedEntity) {
+			ai.Target = sight.TrackedEntity
+			ai.TargetVisible = true
+		}
+
+		if ai.TargetVisible {
+			ai.LastSeen = ai.Target.Position()
+		}
+	case EV_LOSTSIGHT:
+		sight := e.(*EntitySightEvent)
+		ai := ecs.Get[AIComponent](sight.Viewer)
+		if ai == nil || sight.TrackedEntity != ai.Target {
+			return
+		}
+
+		// remember where the target was headed when we lost track of it
+		ai.TargetVisible = false
+		if ecs.Alive(ai.Target) && ai.Target.IsInTilemap() {
+			ai.LastSeen = ai.Target.Position()
+		}
+	case EV_ENTITYBEINGDESTROYED, EV_ENTITYDIED:
+		destroyed := e.(*EntityEvent).Entity
+		for ai := range ecs.EachComponent[AIComponent]() {
+			if ai.Target == destroyed {
+				ai.ClearTarget()
+			}
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (ais *AISystem) isHostile(entity, other Entity) bool {
+	if ais.IsHostile != nil {
+		return ais.IsHostile(entity, other)
+	}
+
+	return other.IsPlayer()
+}
+
+// RunAI runs the AI behaviour for the entity and returns the energy cost of the action it took. Entities without an
+// AIComponent, or whose behaviour doesn't do anything, just wait for a turn.
+func (ais *AISystem) RunAI(entity Entity) (cost int) {
+	ai := ecs.Get[AIComponent](entity)
+	if ai == nil {
+		return ACTION_COST
+	}
+
+	node := GetAINode(ai.Behaviour)
+	if node == nil {
+		log.Error("AI: no behaviour registered with name ", ai.Behaviour)
+		return ACTION_COST
+	}
+
+	if ai.HasTarget() && ai.TargetVisible {
+		ai.LastSeen = ai.Target.Position()
+	}
+
+	ctx := AIContext{TileMap: ais.tileMap, Entity: entity, AI: ai, RNG: ais.RNG}
+	node.Run(&ctx)
+
+	if ctx.Cost <= 0 {
+		return ACTION_COST
+	}
+
+	return ctx.Cost
+}
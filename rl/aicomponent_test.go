@@ -0,0 +1,246 @@
+package rl
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/bennicholls/tyumi/rl/ecs"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+var (
+	aiTestPlayer = RegisterEntityType(EntityData{Name: "ai_test_player", CreateFunction: func(e Entity) {
+		ecs.Add(e, PlayerComponent{})
+	}})
+	aiTestMonster = RegisterEntityType(EntityData{Name: "ai_test_monster", HP: 8, CreateFunction: func(e Entity) {
+		ecs.Add(e, AIComponent{})
+		ecs.Add(e, FOVComponent{SightRange: 4, TrackEntities: true})
+	}})
+)
+
+// makes a 10x5 tilemap with a monster at (4, 2) and a player at (7, 2).
+func newAITestMap(t *testing.T) (tm *TileMap, monster, player Entity) {
+	t.Helper()
+
+	tm = new(TileMap)
+	tm.Init(vec.Dims{10, 5}, saveTestFloor)
+	tm.AISystem.RNG = rand.New(rand.NewPCG(1, 2))
+	monster, player = CreateEntity(aiTestMonster), CreateEntity(aiTestPlayer)
+	tm.AddEntity(monster, vec.Coord{4, 2})
+	tm.AddEntity(player, vec.Coord{7, 2})
+
+	t.Cleanup(func() {
+		ecs.DestroyEntity(monster)
+		ecs.DestroyEntity(player)
+		tm.Cleanup()
+	})
+
+	return
+}
+
+// a node that records when it is run and reports a fixed status.
+func aiTestNode(name string, status AIStatus, ran *[]string) AINode {
+	return AIAction(func(ctx *AIContext) AIStatus {
+		*ran = append(*ran, name)
+		return status
+	})
+}
+
+func TestAICompositeNodes(t *testing.T) {
+	score := func(s float64) func(ctx *AIContext) float64 {
+		return func(ctx *AIContext) float64 { return s }
+	}
+
+	tests := []struct {
+		name   string
+		build  func(ran *[]string) AINode
+		status AIStatus
+		ran    []string
+	}{
+		{"sequence succeeds", func(ran *[]string) AINode {
+			return AISequence{aiTestNode("a", AI_SUCCESS, ran), aiTestNode("b", AI_SUCCESS, ran)}
+		}, AI_SUCCESS, []string{"a", "b"}},
+		{"sequence stops at failure", func(ran *[]string) AINode {
+			return AISequence{aiTestNode("a", AI_SUCCESS, ran), aiTestNode("b", AI_FAILURE, ran), aiTestNode("c", AI_SUCCESS, ran)}
+		}, AI_FAILURE, []string{"a", "b"}},
+		{"sequence stops at running", func(ran *[]string) AINode {
+			return AISequence{aiTestNode("a", AI_RUNNING, ran), aiTestNode("b", AI_SUCCESS, ran)}
+		}, AI_RUNNING, []string{"a"}},
+		{"selector falls through failures", func(ran *[]string) AINode {
+			return AISelector{aiTestNode("a", AI_FAILURE, ran), aiTestNode("b", AI_SUCCESS, ran), aiTestNode("c", AI_SUCCESS, ran)}
+		}, AI_SUCCESS, []string{"a", "b"}},
+		{"selector stops at running", func(ran *[]string) AINode {
+			return AISelector{aiTestNode("a", AI_FAILURE, ran), aiTestNode("b", AI_RUNNING, ran), aiTestNode("c", AI_SUCCESS, ran)}
+		}, AI_RUNNING, []string{"a", "b"}},
+		{"selector fails", func(ran *[]string) AINode {
+			return AISelector{aiTestNode("a", AI_FAILURE, ran), aiTestNode("b", AI_FAILURE, ran)}
+		}, AI_FAILURE, []string{"a", "b"}},
+		{"inverter", func(ran *[]string) AINode {
+			return AISequence{AIInverter{aiTestNode("a", AI_FAILURE, ran)}, AIInverter{aiTestNode("b", AI_RUNNING, ran)}}
+		}, AI_RUNNING, []string{"a", "b"}},
+		{"utility runs best option", func(ran *[]string) AINode {
+			return AIUtility{
+				{score(1), aiTestNode("a", AI_SUCCESS, ran)},
+				{score(3), aiTestNode("b", AI_SUCCESS, ran)},
+				{score(2), aiTestNode("c", AI_SUCCESS, ran)},
+			}
+		}, AI_SUCCESS, []string{"b"}},
+		{"utility falls through to next best", func(ran *[]string) AINode {
+			return AIUtility{
+				{score(1), aiTestNode("a", AI_SUCCESS, ran)},
+				{score(3), aiTestNode("b", AI_FAILURE, ran)},
+				{score(2), aiTestNode("c", AI_RUNNING, ran)},
+			}
+		}, AI_RUNNING, []string{"b", "c"}},
+		{"utility skips options scoring 0", func(ran *[]string) AINode {
+			return AIUtility{
+				{score(0), aiTestNode("a", AI_SUCCESS, ran)},
+				{score(2), aiTestNode("b", AI_FAILURE, ran)},
+				{score(-1), aiTestNode("c", AI_SUCCESS, ran)},
+			}
+		}, AI_FAILURE, []string{"b"}},
+	}
+
+	for _, test := range tests {
+		var ran []string
+		status := test.build(&ran).Run(&AIContext{})
+		if status != test.status || !slices.Equal(ran, test.ran) {
+			t.Errorf("%s: status %d after running %v, wanted %d after running %v", test.name, status, ran, test.status, test.ran)
+		}
+	}
+}
+
+func TestAISightEvents(t *testing.T) {
+	tm, monster, player := newAITestMap(t)
+	bystander := CreateEntity(saveTestRat)
+	tm.AddEntity(bystander, vec.Coord{5, 3})
+
+	tm.FOVSystem.Update(0)
+	ai := ecs.Get[AIComponent](monster)
+	if ai.Target != player || !ai.TargetVisible || ai.LastSeen != player.Position() {
+		t.Fatalf("Gaining sight of player: target %v (visible %v) last seen at %v", ai.Target, ai.TargetVisible, ai.LastSeen)
+	}
+
+	// moving in view updates the last seen position on the AI's next turn
+	tm.MoveEntity(player, vec.Coord{7, 1})
+	tm.RunAI(monster)
+	if ai = ecs.Get[AIComponent](monster); ai.LastSeen != (vec.Coord{7, 1}) || ai.State != AI_HUNT {
+		t.Errorf("Target moved in view: last seen at %v, state %d", ai.LastSeen, ai.State)
+	}
+
+	tm.MoveEntity(player, vec.Coord{9, 4})
+	if ai = ecs.Get[AIComponent](monster); ai.Target != player || ai.TargetVisible || ai.LastSeen != (vec.Coord{9, 4}) {
+		t.Errorf("Losing sight of player: target %v (visible %v) last seen at %v", ai.Target, ai.TargetVisible, ai.LastSeen)
+	}
+
+	tm.MoveEntity(bystander, vec.Coord{9, 0})
+	tm.MoveEntity(bystander, vec.Coord{5, 3})
+	if ai = ecs.Get[AIComponent](monster); ai.Target != player {
+		t.Error("AI targeted a non-hostile entity.")
+	}
+
+	tm.AISystem.IsHostile = func(entity, other Entity) bool { return other == bystander }
+	tm.MoveEntity(bystander, vec.Coord{9, 0})
+	tm.MoveEntity(bystander, vec.Coord{5, 3})
+	if ai = ecs.Get[AIComponent](monster); ai.Target != bystander || !ai.TargetVisible {
+		t.Error("AI did not switch to a hostile entity while its target was out of view.")
+	}
+
+	tm.AISystem.IsHostile = nil
+	tm.MoveEntity(player, vec.Coord{6, 2})
+	if ai = ecs.Get[AIComponent](monster); ai.Target != bystander {
+		t.Error("AI switched targets while its target was in view.")
+	}
+
+	bystander.Destroy()
+	ecs.ProcessQueuedEntities()
+	if ai = ecs.Get[AIComponent](monster); ai.Target.IsValid() || ai.LastSeen != NOT_IN_TILEMAP {
+		t.Error("Destroyed target was not cleared.")
+	}
+}
+
+func TestAIMonster(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(tm *TileMap, ai *AIComponent, health *HealthComponent, player Entity)
+		state AIState
+		pos   vec.Coord // where the monster should end up. ignored for wandering.
+	}{
+		{"hunts visible target", func(tm *TileMap, ai *AIComponent, health *HealthComponent, player Entity) {
+			ai.Target, ai.TargetVisible = player, true
+		}, AI_HUNT, vec.Coord{5, 2}},
+		{"flees when hurt", func(tm *TileMap, ai *AIComponent, health *HealthComponent, player Entity) {
+			ai.Target, ai.TargetVisible = player, true
+			health.HP.Set(2)
+			tm.SetTileType(vec.Coord{3, 3}, saveTestWall) // so there's only one best way to run
+		}, AI_FLEE, vec.Coord{3, 1}},
+		{"searches for lost target", func(tm *TileMap, ai *AIComponent, health *HealthComponent, player Entity) {
+			ai.Target, ai.LastSeen = player, vec.Coord{4, 0}
+		}, AI_SEARCH, vec.Coord{4, 1}},
+		{"doesn't search when hurt", func(tm *TileMap, ai *AIComponent, health *HealthComponent, player Entity) {
+			ai.Target, ai.LastSeen = player, vec.Coord{4, 0}
+			health.HP.Set(2)
+		}, AI_WANDER, vec.Coord{}},
+		{"wanders without a target", func(tm *TileMap, ai *AIComponent, health *HealthComponent, player Entity) {},
+			AI_WANDER, vec.Coord{}},
+		{"waits when boxed in", func(tm *TileMap, ai *AIComponent, health *HealthComponent, player Entity) {
+			for _, dir := range vec.Directions {
+				tm.SetTileType(vec.Coord{4, 2}.Step(dir), saveTestWall)
+			}
+		}, AI_IDLE, vec.Coord{4, 2}},
+	}
+
+	for _, test := range tests {
+		tm, monster, player := newAITestMap(t)
+		test.setup(tm, ecs.Get[AIComponent](monster), ecs.Get[HealthComponent](monster), player)
+
+		start := monster.Position()
+		if cost := tm.RunAI(monster); cost != ACTION_COST {
+			t.Errorf("%s: action cost %d", test.name, cost)
+		}
+
+		ai := ecs.Get[AIComponent](monster)
+		if test.state == AI_WANDER {
+			if pos := monster.Position(); ai.State != AI_WANDER || pos == start || pos.DistanceSqTo(start) > 2 {
+				t.Errorf("%s: state %d, moved from %v to %v", test.name, ai.State, start, pos)
+			}
+		} else if ai.State != test.state || monster.Position() != test.pos {
+			t.Errorf("%s: state %d at %v, wanted state %d at %v", test.name, ai.State, monster.Position(), test.state, test.pos)
+		}
+	}
+}
+
+func TestAIWanderRNG(t *testing.T) {
+	wander := func() (path []vec.Coord) {
+		tm, monster, _ := newAITestMap(t)
+		tm.MoveEntity(monster, vec.Coord{1, 1}) // out of sight of the player
+		for range 10 {
+			tm.RunAI(monster)
+			path = append(path, monster.Position())
+		}
+
+		return
+	}
+
+	if first, second := wander(), wander(); !slices.Equal(first, second) {
+		t.Errorf("Wandering with the same seed went different ways: %v and %v", first, second)
+	}
+}
+
+func TestAIWanderChecksMove(t *testing.T) {
+	tm, monster, _ := newAITestMap(t)
+
+	// monster thinks it's at (1, 1), but isn't in the tile there, so the tilemap won't move it
+	tm.RemoveEntity(monster)
+	ecs.Get[PositionComponent](monster).Coord = vec.Coord{1, 1}
+
+	ctx := AIContext{TileMap: tm, Entity: monster, AI: ecs.Get[AIComponent](monster), RNG: tm.AISystem.RNG}
+	if status := AIWander.Run(&ctx); status != AI_FAILURE || ctx.Cost != 0 {
+		t.Errorf("Wander reported status %d with cost %d for a move that didn't happen.", status, ctx.Cost)
+	}
+
+	if ctx.StepToward(vec.Coord{5, 1}) || ctx.Cost != 0 {
+		t.Error("StepToward reported a move that didn't happen.")
+	}
+}
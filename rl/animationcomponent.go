@@ -98,3 +98,16 @@ func (as *AnimationSystem) Update(delta time.Duration) {
 		ecs.Remove[AnimationComponent](entity)
 	}
 }
+
+// checkBlocking recomputes HasBlockingAnimation, catching any blocking animations added since the last update.
+func (as *AnimationSystem) checkBlocking() bool {
+	as.HasBlockingAnimation = false
+	for animComp := range ecs.EachComponent[AnimationComponent]() {
+		if animComp.HasBlockingAnimation() {
+			as.HasBlockingAnimation = true
+			break
+		}
+	}
+
+	return as.HasBlockingAnimation
+}
@@ -0,0 +1,94 @@
+package rl
+
+import (
+	"math/rand/v2"
+
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/rl/ecs"
+)
+
+var EV_ENTITYATTACKED = event.Register("An entity was attacked.")
+
+// DamageType describes the kind of damage dealt by an attack. Entities can resist (or be weak to) each type of damage
+// differently. Games can define their own types beyond the ones here, just start after DAMAGE_USER.
+type DamageType uint8
+
+const (
+	DAMAGE_PHYSICAL DamageType = iota // reduced by armour
+	DAMAGE_FIRE
+	DAMAGE_COLD
+	DAMAGE_ELECTRIC
+	DAMAGE_POISON
+	DAMAGE_TRUE // ignores resistances and armour. shields still absorb it though.
+	DAMAGE_USER // first damage type available for games to define
+)
+
+// CRIT_MULTIPLIER is the default damage multiplier for critical hits, as a percentage.
+const CRIT_MULTIPLIER int = 200
+
+// Attack describes an attack to be resolved with ResolveAttack().
+type Attack struct {
+	Damage         int
+	Type           DamageType
+	CritChance     int // percent chance (0-100) of the attack being a critical hit
+	CritMultiplier int // damage multiplier for critical hits, as a percentage. if 0, CRIT_MULTIPLIER is used.
+
+	RNG *rand.Rand // source of randomness for rolling crits. if nil, the global source is used.
+}
+
+// AttackResult describes what happened when an attack was resolved.
+type AttackResult struct {
+	Amount   int // HP lost by the defender
+	Blocked  int // damage prevented by the defender's resistances, armour and shield
+	Type     DamageType
+	Critical bool
+	Killed   bool // true if the attack killed the defender
+}
+
+// AttackEvent is fired whenever an attack is resolved, before the defender's health is changed. So if the attack kills
+// the defender, this is fired before EV_ENTITYDIED.
+type AttackEvent struct {
+	event.EventPrototype
+
+	Attacker, Defender Entity
+	AttackResult
+}
+
+// ResolveAttack has the attacker attack the defender. Critical hits are rolled for, and the damage is applied to the
+// defender's HealthComponent (see HealthComponent.Damage() for how damage is reduced). EV_ENTITYATTACKED is fired with
+// the result so UIs and message logs can report what happened. Attacks on entities without a HealthComponent, or
+// that are already dead, have no effect.
+func ResolveAttack(attacker, defender Entity, attack Attack) (result AttackResult) {
+	health := ecs.Get[HealthComponent](defender)
+	if health == nil || health.IsDead() {
+		return
+	}
+
+	result.Type = attack.Type
+	damage := attack.Damage
+	if attack.CritChance > 0 && attack.rollPercent() < attack.CritChance {
+		result.Critical = true
+		multiplier := attack.CritMultiplier
+		if multiplier == 0 {
+			multiplier = CRIT_MULTIPLIER
+		}
+		damage = damage * multiplier / 100
+	}
+
+	result.Amount, result.Blocked = health.mitigate(damage, attack.Type)
+	result.Killed = result.Amount > 0 && health.HP.toMin() == result.Amount // mitigate never deals more than this
+
+	event.Fire(EV_ENTITYATTACKED, &AttackEvent{Attacker: attacker, Defender: defender, AttackResult: result})
+	health.ChangeHealth(-result.Amount)
+
+	return
+}
+
+// returns a random number in [0, 100) using the attack's RNG.
+func (a Attack) rollPercent() int {
+	if a.RNG == nil {
+		return rand.IntN(100)
+	}
+
+	return a.RNG.IntN(100)
+}
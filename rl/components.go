@@ -1,18 +1,22 @@
 package rl
 
 import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
 	"github.com/bennicholls/tyumi/log"
 	"github.com/bennicholls/tyumi/rl/ecs"
 	"github.com/bennicholls/tyumi/vec"
 )
 
 func init() {
-	ecs.Register[TerrainComponent]()
-	ecs.Register[EntityContainerComponent]()
-	ecs.Register[PositionComponent]()
-	ecs.Register[EntityComponent]()
-	ecs.Register[PlayerComponent]()
-	ecs.Register[MemoryComponent]()
+	ecs.Register[TerrainComponent]("rl.Terrain")
+	ecs.Register[EntityContainerComponent]("rl.EntityContainer")
+	ecs.Register[PositionComponent]("rl.Position")
+	ecs.Register[EntityComponent]("rl.Entity")
+	ecs.Register[PlayerComponent]("rl.Player")
+	ecs.Register[MemoryComponent]("rl.Memory")
 }
 
 type PositionComponent struct {
@@ -27,6 +31,27 @@ type TerrainComponent struct {
 	TileType
 }
 
+// terrain is saved using the name of the tile type, so saves don't break if tile types are registered in a different
+// order.
+func (tc TerrainComponent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tc.TileType.Data().Name)
+}
+
+func (tc *TerrainComponent) UnmarshalJSON(data []byte) (err error) {
+	var name string
+	if err = json.Unmarshal(data, &name); err != nil {
+		return
+	}
+
+	tileType, ok := GetTileTypeByName(name)
+	if !ok {
+		return fmt.Errorf("no tile type registered with name %q", name)
+	}
+
+	tc.TileType = tileType
+	return
+}
+
 type EntityComponent struct {
 	ecs.Component
 	EntityType
@@ -34,16 +59,55 @@ type EntityComponent struct {
 	Invisible bool
 }
 
+// entity components are saved using the name of the entity type, so saves don't break if entity types are
+// registered in a different order.
+type entityComponentJSON struct {
+	EntityType string
+	Invisible  bool
+}
+
+func (ec EntityComponent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(entityComponentJSON{ec.EntityType.GetData().Name, ec.Invisible})
+}
+
+func (ec *EntityComponent) UnmarshalJSON(data []byte) (err error) {
+	var ecj entityComponentJSON
+	if err = json.Unmarshal(data, &ecj); err != nil {
+		return
+	}
+
+	entityType, ok := GetEntityTypeByName(ecj.EntityType)
+	if !ok {
+		return fmt.Errorf("no entity type registered with name %q", ecj.EntityType)
+	}
+
+	ec.EntityType = entityType
+	ec.Invisible = ecj.Invisible
+	return
+}
+
 type PlayerComponent struct {
 	ecs.Component
 }
 
+// EntityContainerComponent holds the entities in a tile. A tile can hold one blocking entity (a monster, the player,
+// a big statue, etc.) and any number of passable entities (items, decorations, etc.) in a pile beneath it.
 type EntityContainerComponent struct {
 	ecs.Component
 
-	Entity
+	Entity          // the blocking entity in the tile, if any
+	Pile   []Entity // passable entities in the tile, in the order they were added. the last one is on top.
+}
+
+func (ecc *EntityContainerComponent) RemapEntities(remap ecs.EntityRemap) {
+	ecc.Entity = Entity(remap.Remap(ecs.Entity(ecc.Entity)))
+	for i, entity := range ecc.Pile {
+		ecc.Pile[i] = Entity(remap.Remap(ecs.Entity(entity)))
+	}
+	ecc.Pile = slices.DeleteFunc(ecc.Pile, func(e Entity) bool { return !e.IsValid() })
 }
 
+// Empty reports whether the container has no blocking entity. There may still be entities in the pile.
 func (ecc EntityContainerComponent) Empty() bool {
 	return ecc.Entity == Entity(ecs.INVALID_ID)
 }
@@ -58,3 +122,28 @@ func (ecc *EntityContainerComponent) Add(entity Entity) {
 func (ecc *EntityContainerComponent) Remove() {
 	ecc.Entity = Entity(ecs.INVALID_ID)
 }
+
+// AddToPile puts the entity on top of the pile.
+func (ecc *EntityContainerComponent) AddToPile(entity Entity) {
+	if slices.Contains(ecc.Pile, entity) {
+		return
+	}
+
+	ecc.Pile = append(ecc.Pile, entity)
+}
+
+// RemoveFromPile takes the entity out of the pile. Returns false if the entity wasn't in the pile.
+func (ecc *EntityContainerComponent) RemoveFromPile(entity Entity) bool {
+	idx := slices.Index(ecc.Pile, entity)
+	if idx == -1 {
+		return false
+	}
+
+	ecc.Pile = slices.Delete(ecc.Pile, idx, idx+1)
+	return true
+}
+
+// Contains reports whether the entity is in the container, either as the blocking entity or in the pile.
+func (ecc EntityContainerComponent) Contains(entity Entity) bool {
+	return entity.IsValid() && (ecc.Entity == entity || slices.Contains(ecc.Pile, entity))
+}
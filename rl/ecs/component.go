@@ -32,18 +32,39 @@ func (c *Component) Init() {}
 // Cleanup is run when the component is removed from an entity. Use this to... I dunno, send events?
 func (c *Component) Cleanup() {}
 
+// RemapEntities is run when the component is loaded from a save (see Load()), just before it is added to its entity.
+// Loaded entities are given new IDs, so if your component stores references to other entities, use the remap to
+// translate them here.
+func (c *Component) RemapEntities(remap EntityRemap) {}
+
 // Register registers a type to be used as a component for entities. Types MUST be registered before being
 // added to entities. Trying to add, get, or remove an unregistered component to/from an entity results in a panic.
-func Register[T componentType]() {
+// Optionally takes a name for the component type, which opts it in to being saved by Save() and SaveEntities(). The
+// name identifies the component's data in saves, so it should never change once you have saves you care about.
+func Register[T componentType](name ...string) {
 	t := reflect.TypeFor[T]()
 	if _, ok := typeMap[t]; ok { // duplicate register
 		log.Debug("ECS: Duplicate component register! " + t.Name() + " already registered.")
 		return
 	}
 
+	var componentName string
+	if len(name) > 0 && name[0] != "" {
+		componentName = name[0]
+		if _, ok := componentNameMap[componentName]; ok {
+			log.Error("ECS: Could not register " + t.Name() + ": component name " + componentName + " already in use.")
+			return
+		}
+	}
+
 	var newCache componentCache[T]
 	componentCaches = append(componentCaches, &newCache)
+	componentNames = append(componentNames, componentName)
 	typeMap[t] = componentID(len(componentCaches) - 1)
+
+	if componentName != "" {
+		componentNameMap[componentName] = typeMap[t]
+	}
 }
 
 // Add adds a new component of type T to an entity. The component type must be registered; if not, a panic
@@ -1,6 +1,7 @@
 package ecs
 
 import (
+	"encoding/json"
 	"reflect"
 
 	"github.com/bennicholls/tyumi/log"
@@ -11,12 +12,15 @@ type settableComponentType interface {
 	setEntity(e Entity)
 	Init()
 	Cleanup()
+	RemapEntities(remap EntityRemap)
 }
 
 type componentID uint32
 
 var componentCaches []componentContainer
 var typeMap map[reflect.Type]componentID
+var componentNames []string                 // names of registered components, indexed by componentID. empty if unnamed.
+var componentNameMap map[string]componentID // map of component names to component IDs, for loading
 
 // this defines a component cache. component caches like to return the actual component type for add and get operations,
 // so we can't put those functions in the interface here.
@@ -24,11 +28,15 @@ type componentContainer interface {
 	copyComponent(id Entity, new_id Entity)
 	hasComponent(id Entity) bool
 	removeComponent(id Entity)
+	marshalComponent(id Entity) (json.RawMessage, error)
+	unmarshalComponent(id Entity, data json.RawMessage, remap EntityRemap) error
 }
 
 func init() {
 	componentCaches = make([]componentContainer, 0, 20)
 	typeMap = make(map[reflect.Type]componentID)
+	componentNames = make([]string, 0, 20)
+	componentNameMap = make(map[string]componentID)
 }
 
 type componentCache[T componentType] struct {
@@ -110,6 +118,25 @@ func (cc *componentCache[T]) removeComponent(entity Entity) {
 	cc.components = cc.components[:endIndex] // reslice component list to new len
 }
 
+func (cc *componentCache[T]) marshalComponent(entity Entity) (json.RawMessage, error) {
+	return json.Marshal(cc.components[cc.indices[entity]])
+}
+
+// decodes saved data into a new component and adds it to the entity. the data is decoded first so the component's
+// Init() function can see the loaded values.
+func (cc *componentCache[T]) unmarshalComponent(entity Entity, data json.RawMessage, remap EntityRemap) error {
+	var component T
+	if err := json.Unmarshal(data, &component); err != nil {
+		return err
+	}
+
+	var i any = &component
+	i.(settableComponentType).RemapEntities(remap)
+	cc.addComponent(entity, component)
+
+	return nil
+}
+
 func getComponentCache[T componentType]() *componentCache[T] {
 	componentType := reflect.TypeFor[T]()
 	componentID, ok := typeMap[componentType]
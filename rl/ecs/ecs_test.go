@@ -1,6 +1,8 @@
 package ecs
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/bennicholls/tyumi/util"
@@ -13,6 +15,29 @@ type testComponent struct {
 	vec.Coord
 }
 
+type testLinkComponent struct {
+	Component
+
+	Name   string
+	Target Entity
+}
+
+func (tlc *testLinkComponent) RemapEntities(remap EntityRemap) {
+	tlc.Target = remap.Remap(tlc.Target)
+}
+
+// records in Init() whether saved data was already present, like components that set themselves up from their fields.
+type testInitComponent struct {
+	Component
+
+	Value      int
+	initedWith int
+}
+
+func (tic *testInitComponent) Init() {
+	tic.initedWith = tic.Value
+}
+
 func countAliveEntities() (count int) {
 	for _, entity := range entities {
 		if entity != INVALID_ID {
@@ -138,3 +163,95 @@ func TestAddRemoveAlterComponents(t *testing.T) {
 		t.Errorf("Found %d components, expected %d", componentsFound, componentsAdded-componentsRemoved)
 	}
 }
+
+func TestSaveLoad(t *testing.T) {
+	Register[testLinkComponent]("test_link")
+	tag := RegisterTag("test_tag")
+
+	first, second := CreateEntity(), CreateEntity()
+	Add(first, testLinkComponent{Name: "first", Target: second})
+	Add(second, testLinkComponent{Name: "second", Target: first})
+	AddTag(second, tag)
+
+	var buf bytes.Buffer
+	if err := SaveEntities(&buf, func(yield func(Entity) bool) {
+		_ = yield(first) && yield(second)
+	}); err != nil {
+		t.Fatal("Save failed: ", err)
+	}
+
+	DestroyEntity(first)
+	DestroyEntity(second)
+	RemoveTag(second, tag)
+
+	remap, err := Load(&buf)
+	if err != nil {
+		t.Fatal("Load failed: ", err)
+	}
+
+	if len(remap) != 2 {
+		t.Fatalf("Loaded %d entities, wanted 2", len(remap))
+	}
+
+	loadedFirst, loadedSecond := remap.Remap(first), remap.Remap(second)
+	if !Alive(loadedFirst) || !Alive(loadedSecond) {
+		t.Fatal("Loaded entities not alive.")
+	}
+
+	if link := Get[testLinkComponent](loadedFirst); link == nil || link.Name != "first" || link.Target != loadedSecond {
+		t.Errorf("First entity loaded incorrectly: %v", link)
+	}
+
+	if link := Get[testLinkComponent](loadedSecond); link == nil || link.Name != "second" || link.Target != loadedFirst {
+		t.Errorf("Second entity loaded incorrectly: %v", link)
+	}
+
+	if !HasTag(loadedSecond, tag) || HasTag(loadedFirst, tag) {
+		t.Errorf("Tags loaded incorrectly.")
+	}
+}
+
+func TestLoadInitSeesSavedFields(t *testing.T) {
+	Register[testInitComponent]("test_init")
+
+	entity := CreateEntity()
+	Add(entity, testInitComponent{Value: 42})
+
+	var buf bytes.Buffer
+	if err := SaveEntities(&buf, func(yield func(Entity) bool) { yield(entity) }); err != nil {
+		t.Fatal("Save failed: ", err)
+	}
+	DestroyEntity(entity)
+
+	remap, err := Load(&buf)
+	if err != nil {
+		t.Fatal("Load failed: ", err)
+	}
+
+	loaded := Get[testInitComponent](remap.Remap(entity))
+	if loaded == nil || loaded.Value != 42 {
+		t.Fatalf("Component loaded incorrectly: %v", loaded)
+	}
+
+	if loaded.initedWith != 42 {
+		t.Errorf("Init() ran before saved data was decoded: saw %d, wanted 42", loaded.initedWith)
+	}
+}
+
+func TestLoadMalformedComponent(t *testing.T) {
+	Register[testInitComponent]("test_init")
+
+	before := countAliveEntities()
+	data := `{"id": 1, "components": {"test_init": {"Value": 1}}}
+{"id": 2, "components": {"test_init": {"Value": "not a number"}}}
+{"id": 3}
+`
+
+	if _, err := Load(strings.NewReader(data)); err == nil {
+		t.Fatal("Load succeeded with a malformed component.")
+	}
+
+	if after := countAliveEntities(); after != before {
+		t.Errorf("Failed load leaked %d entities.", after-before)
+	}
+}
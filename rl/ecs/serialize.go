@@ -0,0 +1,151 @@
+package ecs
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+
+	"github.com/bennicholls/tyumi/log"
+)
+
+// Serialization writes entities to a stream as JSON, one entity per line, and can read them back into the ECS later.
+// Only components and tags registered with a name are saved; the name is what identifies the data in the stream, so
+// it must stay the same between versions of your program for old saves to load. Only exported fields of components
+// are saved (this is done with encoding/json, so struct tags work as you'd expect).
+//
+// Loaded entities are given new IDs. Components holding references to other entities can implement RemapEntities()
+// to translate them to the new IDs.
+
+// entityRecord is the saved form of an entity.
+type entityRecord struct {
+	ID         Entity                     `json:"id"`
+	Components map[string]json.RawMessage `json:"components,omitempty"`
+	Tags       []string                   `json:"tags,omitempty"`
+}
+
+// EntityRemap maps entity IDs from a saved stream to the IDs of the entities created when the stream was loaded.
+type EntityRemap map[Entity]Entity
+
+// Remap returns the loaded ID of a saved entity. IDs that were not part of the save are remapped to INVALID_ID.
+func (er EntityRemap) Remap(entity Entity) Entity {
+	return er[entity]
+}
+
+// EachAliveEntity is an iterator over every entity that has not been destroyed.
+func EachAliveEntity() iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		for _, entity := range entities {
+			if entity != INVALID_ID && !yield(entity) {
+				return
+			}
+		}
+	}
+}
+
+// Save writes every live entity, along with its named components and tags, to the writer.
+func Save(w io.Writer) error {
+	return SaveEntities(w, EachAliveEntity())
+}
+
+// SaveEntities writes the provided entities, along with their named components and tags, to the writer. Dead
+// entities are skipped.
+func SaveEntities(w io.Writer, entities iter.Seq[Entity]) error {
+	encoder := json.NewEncoder(w)
+	for entity := range entities {
+		if !Alive(entity) {
+			continue
+		}
+
+		record := entityRecord{ID: entity}
+		for id, cache := range componentCaches {
+			if componentNames[id] == "" || !cache.hasComponent(entity) {
+				continue
+			}
+
+			data, err := cache.marshalComponent(entity)
+			if err != nil {
+				log.Error("ECS: Could not save ", componentNames[id], " component: ", err)
+				return err
+			}
+
+			if record.Components == nil {
+				record.Components = make(map[string]json.RawMessage)
+			}
+			record.Components[componentNames[id]] = data
+		}
+
+		for tag, name := range tagNames {
+			if name != "" && tagCaches[tag].Contains(entity) {
+				record.Tags = append(record.Tags, name)
+			}
+		}
+
+		if err := encoder.Encode(record); err != nil {
+			log.Error("ECS: Could not save entity: ", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Load reads entities from a stream written by Save() or SaveEntities() and creates them in the ECS. Each loaded
+// entity gets a new ID; the returned remap translates saved IDs to the new ones, so you can find entities that you
+// kept track of (the player, for example). Components and tags whose names are not registered are skipped with a
+// warning. If loading fails, any entities created so far are destroyed.
+func Load(r io.Reader) (remap EntityRemap, err error) {
+	records := make([]entityRecord, 0)
+	decoder := json.NewDecoder(r)
+	for {
+		var record entityRecord
+		if err = decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+				break
+			}
+
+			log.Error("ECS: Could not load entities: ", err)
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	// create all entities first so references between them can be remapped
+	remap = make(EntityRemap, len(records))
+	for _, record := range records {
+		remap[record.ID] = CreateEntity()
+	}
+
+	for _, record := range records {
+		entity := remap[record.ID]
+		for name, data := range record.Components {
+			id, ok := componentNameMap[name]
+			if !ok {
+				log.Warning("ECS: Could not load component ", name, ": no component registered with that name.")
+				continue
+			}
+
+			if err = componentCaches[id].unmarshalComponent(entity, data, remap); err != nil {
+				log.Error("ECS: Could not load ", name, " component: ", err)
+				for _, created := range remap {
+					DestroyEntity(created)
+				}
+				return nil, err
+			}
+		}
+
+		for _, name := range record.Tags {
+			tag, ok := tagNameMap[name]
+			if !ok {
+				log.Warning("ECS: Could not load tag ", name, ": no tag registered with that name.")
+				continue
+			}
+
+			AddTag(entity, tag)
+		}
+	}
+
+	return
+}
@@ -12,15 +12,37 @@ func (t Tag) isValid() bool {
 }
 
 var tagCaches []util.Set[Entity]
+var tagNames []string         // names of registered tags, indexed by tag. empty if unnamed.
+var tagNameMap map[string]Tag // map of tag names to tags, for loading
 
 func init() {
 	tagCaches = make([]util.Set[Entity], 0)
+	tagNames = make([]string, 0)
+	tagNameMap = make(map[string]Tag)
 }
 
-func RegisterTag() Tag {
+// RegisterTag creates a new tag. Optionally takes a name for the tag, which opts it in to being saved by Save() and
+// SaveEntities(). Like component names, the name should never change once you have saves you care about.
+func RegisterTag(name ...string) Tag {
+	var tagName string
+	if len(name) > 0 && name[0] != "" {
+		tagName = name[0]
+		if tag, ok := tagNameMap[tagName]; ok {
+			log.Debug("ECS: Duplicate tag register! Tag " + tagName + " already registered.")
+			return tag
+		}
+	}
+
 	newTagSet := util.Set[Entity]{}
 	tagCaches = append(tagCaches, newTagSet)
-	return Tag(len(tagCaches) - 1)
+	tagNames = append(tagNames, tagName)
+	tag := Tag(len(tagCaches) - 1)
+
+	if tagName != "" {
+		tagNameMap[tagName] = tag
+	}
+
+	return tag
 }
 
 func AddTag[ET ~uint32](entity ET, tag Tag) {
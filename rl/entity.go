@@ -20,14 +20,32 @@ type EntityData struct {
 	Desc           string // Generic description for the entity
 	HP             int    // HP this entity starts with. If zero, entity is undamagable.
 	Visuals        gfx.Visuals
-	Invisible      bool // if this entity can be seen by non-omniscient beings.
+	Invisible      bool           // if this entity can be seen by non-omniscient beings.
+	Passable       bool           // if true, the entity doesn't block its tile. it goes in the tile's pile with items and such.
+	DrawPriority   int            // passable entities with higher priority are drawn over the rest of the pile
 	CreateFunction func(e Entity) // function run on the created entity. put custom config steps here!
 }
 
 var entityDataCache util.DataCache[EntityData, EntityType]
+var entityTypeNames map[string]EntityType = make(map[string]EntityType)
 
+// RegisterEntityType registers a new type of entity. Entity types are saved by name (see TileMap.Save()), so names
+// should be unique and shouldn't change once you have saves you care about.
 func RegisterEntityType(entity_data EntityData) EntityType {
-	return entityDataCache.RegisterDataType(entity_data)
+	entityType := entityDataCache.RegisterDataType(entity_data)
+	if _, ok := entityTypeNames[entity_data.Name]; ok {
+		log.Warning("Entity type name ", entity_data.Name, " is already in use! Entities of this type will not load correctly.")
+	} else {
+		entityTypeNames[entity_data.Name] = entityType
+	}
+
+	return entityType
+}
+
+// GetEntityTypeByName returns the registered entity type with the provided name.
+func GetEntityTypeByName(name string) (entity_type EntityType, ok bool) {
+	entity_type, ok = entityTypeNames[name]
+	return
 }
 
 // Entity represents a tilemap object. Each tile can hold one entity (at most). Examples of entities would be things
@@ -110,6 +128,11 @@ func (e Entity) IsPlayer() bool {
 	return ecs.Has[PlayerComponent](e)
 }
 
+// IsPassable reports whether the entity can share its tile with other entities.
+func (e Entity) IsPassable() bool {
+	return e.GetEntityData().Passable
+}
+
 func (e Entity) IsValid() bool {
 	return e != INVALID_ENTITY
 }
@@ -126,6 +149,16 @@ func (e Entity) MoveTo(pos vec.Coord) {
 	event.Fire(EV_ENTITYMOVED, &EntityMovedEvent{Entity: e, From: oldPos, To: pos})
 }
 
+// Face turns the entity to face the provided direction. Entities don't track their own facing, but components that
+// care (like directional lights) do, so this emits EV_ENTITYTURNED to let them know.
+func (e Entity) Face(dir vec.Direction) {
+	if dir == vec.DIR_NONE {
+		return
+	}
+
+	event.Fire(EV_ENTITYTURNED, &EntityTurnedEvent{Entity: e, Facing: dir})
+}
+
 func (e Entity) IsInTilemap() bool {
 	return ecs.Get[PositionComponent](e).Coord != NOT_IN_TILEMAP
 }
@@ -8,9 +8,14 @@ import (
 var (
 	EV_ENTITYBEINGDESTROYED  = event.Register("Entity being destroyed/removed from the ECS.")
 	EV_ENTITYMOVED           = event.Register("Entity moved.")
+	EV_ENTITYTURNED          = event.Register("Entity turned to face a new direction.")
 	EV_ENTITYHEALTHCHANGED   = event.Register("Entity's health changed.")
 	EV_ENTITYDIED            = event.Register("Entity has been killed/destroyed.")
+	EV_TURNENDED             = event.Register("An actor finished its turn.")
 	EV_TILECHANGEDVISIBILITY = event.Register("A Tile Changed visibility state (opaque or transparent)")
+	EV_TILECHANGED           = event.Register("A Tile was replaced or changed type.")
+	EV_TILEITEMADDED         = event.Register("A passable entity was added to a tile's pile.")
+	EV_TILEITEMREMOVED       = event.Register("A passable entity was removed from a tile's pile.")
 	EV_LOSTSIGHT             = event.Register("An entity has lost sight of another entity.")
 	EV_GAINEDSIGHT           = event.Register("An entity has gained sight of another entity.")
 )
@@ -32,6 +37,13 @@ func fireEntityMovedEvent(entity Entity, from, to vec.Coord) {
 	event.Fire(EV_ENTITYMOVED, &EntityMovedEvent{Entity: entity, From: from, To: to})
 }
 
+type EntityTurnedEvent struct {
+	event.EventPrototype
+
+	Entity Entity
+	Facing vec.Direction
+}
+
 type TileChangedVisibilityEvent struct {
 	event.EventPrototype
 
@@ -39,6 +51,21 @@ type TileChangedVisibilityEvent struct {
 	Opaque bool
 }
 
+type TileChangedEvent struct {
+	event.EventPrototype
+
+	Pos vec.Coord
+}
+
+// TileItemEvent is fired when passable entities (items, decorations, etc.) are added to or removed from the pile in a
+// tile.
+type TileItemEvent struct {
+	event.EventPrototype
+
+	Entity Entity
+	Pos    vec.Coord
+}
+
 type EntitySightEvent struct {
 	event.EventPrototype
 
@@ -1,6 +1,7 @@
 package rl
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/bennicholls/tyumi/event"
@@ -12,7 +13,7 @@ import (
 )
 
 func init() {
-	ecs.Register[FOVComponent]()
+	ecs.Register[FOVComponent]("rl.FOV")
 }
 
 // FOVComponent is for anything that can see.
@@ -24,6 +25,10 @@ type FOVComponent struct {
 	Dirty         bool  // if true, FOV needs to recompute
 	SightRange    uint8 // range of FOV in tiles
 	TrackEntities bool
+	Algorithm     FOVAlgorithm  // algorithm used to compute the FOV. Defaults to FOV_SHADOWCAST.
+	Facing        vec.Direction // direction the viewer is looking. Ignored unless Arc is set.
+	Arc           uint16        // width of the viewer's vision cone in degrees, centered on Facing. If 0 (default) or 360+, the viewer sees all around.
+	FixedFacing   bool          // if true, facing doesn't follow the entity when it moves or turns.
 
 	field    util.Set[vec.Coord]
 	entities util.Set[Entity]
@@ -70,6 +75,40 @@ func (fov *FOVComponent) SetSightRange(sight_range uint8) {
 	fov.Dirty = true
 }
 
+func (fov *FOVComponent) SetAlgorithm(algorithm FOVAlgorithm) {
+	if fov.Algorithm == algorithm {
+		return
+	}
+
+	fov.Algorithm = algorithm
+	fov.Dirty = true
+}
+
+func (fov *FOVComponent) SetFacing(dir vec.Direction) {
+	if fov.Facing == dir || dir == vec.DIR_NONE {
+		return
+	}
+
+	fov.Facing = dir
+	if fov.IsCone() {
+		fov.Dirty = true
+	}
+}
+
+func (fov *FOVComponent) SetArc(arc uint16) {
+	if fov.Arc == arc {
+		return
+	}
+
+	fov.Arc = arc
+	fov.Dirty = true
+}
+
+// IsCone reports whether the viewer only sees in a cone, as opposed to all around.
+func (fov FOVComponent) IsCone() bool {
+	return fov.Arc > 0 && fov.Arc < 360
+}
+
 func (fov *FOVComponent) InFOV(pos vec.Coord) bool {
 	if fov.Omniscient {
 		return true
@@ -94,7 +133,7 @@ type FOVSystem struct {
 
 func (fs *FOVSystem) Init(tm *TileMap) {
 	fs.tileMap = tm
-	fs.Listen(EV_ENTITYMOVED, EV_TILECHANGEDVISIBILITY)
+	fs.Listen(EV_ENTITYMOVED, EV_ENTITYTURNED, EV_TILECHANGEDVISIBILITY)
 	fs.SetImmediateEventHandler(fs.immediateHandleEvents)
 }
 
@@ -105,6 +144,9 @@ func (fs *FOVSystem) immediateHandleEvents(e event.Event) (event_handled bool) {
 		for fov, entity := range ecs.EachComponent[FOVComponent]() {
 			if Entity(entity) == moveEvent.Entity {
 				fov.Dirty = true
+				if !fov.FixedFacing && moveEvent.From != NOT_IN_TILEMAP && moveEvent.To != NOT_IN_TILEMAP {
+					fov.SetFacing(moveEvent.From.DirectionTo(moveEvent.To))
+				}
 				continue
 			}
 
@@ -127,6 +169,12 @@ func (fs *FOVSystem) immediateHandleEvents(e event.Event) (event_handled bool) {
 			}
 		}
 
+		return true
+	case EV_ENTITYTURNED:
+		turnEvent := e.(*EntityTurnedEvent)
+		if fov := ecs.Get[FOVComponent](turnEvent.Entity); fov != nil && !fov.FixedFacing {
+			fov.SetFacing(turnEvent.Facing)
+		}
 		return true
 	case EV_TILECHANGEDVISIBILITY:
 		visEvent := e.(*TileChangedVisibilityEvent)
@@ -223,7 +271,8 @@ func (fs *FOVSystem) computeFOV(fov *FOVComponent) {
 	}
 
 	var newField util.Set[vec.Coord]
-	fs.tileMap.ShadowCast(pos, int(fov.SightRange), GetSpacesSetCast(&newField))
+	cast := ConeCast(pos, fov.Facing, int(fov.Arc), GetSpacesSetCast(&newField))
+	fs.tileMap.CastFOV(fov.Algorithm, pos, int(fov.SightRange), cast)
 
 	// Update Memory Component (if present)!
 	if memory := ecs.Get[MemoryComponent](fov.GetEntity()); memory != nil {
@@ -259,7 +308,44 @@ type MemoryComponent struct {
 }
 
 func (mc *MemoryComponent) Init() {
-	mc.memory = make(map[vec.Coord]Memory)
+	if mc.memory == nil {
+		mc.memory = make(map[vec.Coord]Memory)
+	}
+}
+
+// the memory map is unexported (and keyed by coord, which json can't do) so we save it as a list.
+type memoryComponentJSON struct {
+	Colours  col.Pair
+	Memories []savedMemory
+}
+
+type savedMemory struct {
+	Pos vec.Coord
+	Memory
+}
+
+func (mc MemoryComponent) MarshalJSON() ([]byte, error) {
+	mcj := memoryComponentJSON{Colours: mc.Colours, Memories: make([]savedMemory, 0, len(mc.memory))}
+	for pos, memory := range mc.memory {
+		mcj.Memories = append(mcj.Memories, savedMemory{pos, memory})
+	}
+
+	return json.Marshal(mcj)
+}
+
+func (mc *MemoryComponent) UnmarshalJSON(data []byte) error {
+	var mcj memoryComponentJSON
+	if err := json.Unmarshal(data, &mcj); err != nil {
+		return err
+	}
+
+	mc.Colours = mcj.Colours
+	mc.memory = make(map[vec.Coord]Memory, len(mcj.Memories))
+	for _, saved := range mcj.Memories {
+		mc.memory[saved.Pos] = saved.Memory
+	}
+
+	return nil
 }
 
 func (mc MemoryComponent) HasMemory(pos vec.Coord) bool {
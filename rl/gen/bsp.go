@@ -0,0 +1,160 @@
+package gen
+
+import (
+	"math/rand/v2"
+
+	"github.com/bennicholls/tyumi/rl"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// BSPGenerator makes classic rooms-and-corridors levels using binary space partitioning. The map is recursively split
+// into smaller and smaller areas, a room is placed in each one, and then sibling areas are joined by corridors. Every
+// room is reachable from every other room. Each room is a region in the resulting Layout, and rooms joined by a
+// corridor are linked, including any rooms the corridor cuts through.
+type BSPGenerator struct {
+	MinLeafSize int // smallest size an area can be split into. defaults to 8.
+	MinRoomSize int // smallest width/height of a room. defaults to 3.
+	MaxDepth    int // maximum number of times to split the map. if 0, splits until areas are too small to split.
+	Floor       rl.TileType
+	Wall        rl.TileType
+}
+
+// bspNode is an area of the map in the BSP tree. leaves contain rooms, other nodes have 2 children.
+type bspNode struct {
+	area        vec.Rect
+	left, right *bspNode
+	room        int // index of room in the layout, for leaves
+}
+
+func (g BSPGenerator) minLeafSize() int {
+	if g.MinLeafSize <= 0 {
+		return 8
+	}
+
+	return g.MinLeafSize
+}
+
+func (g BSPGenerator) minRoomSize() int {
+	if g.MinRoomSize <= 0 {
+		return 3
+	}
+
+	return g.MinRoomSize
+}
+
+// Generate fills the tilemap with walls and carves out rooms and corridors.
+func (g BSPGenerator) Generate(tm *rl.TileMap, seed uint64) (layout Layout) {
+	rng := newRNG(seed)
+	fill(tm, tm.Bounds(), g.Wall)
+
+	root := &bspNode{area: tm.Bounds().Contracted(1)}
+	g.split(rng, root, 0)
+	g.placeRooms(rng, tm, root, &layout)
+	g.connect(rng, tm, root, &layout)
+
+	return
+}
+
+func (g BSPGenerator) split(rng *rand.Rand, node *bspNode, depth int) {
+	if g.MaxDepth > 0 && depth >= g.MaxDepth {
+		return
+	}
+
+	minSize := g.minLeafSize()
+	canSplitH := node.area.W >= minSize*2 // split with a vertical line, making a left and right area
+	canSplitV := node.area.H >= minSize*2 // split with a horizontal line, making a top and bottom area
+
+	var splitH bool
+	switch {
+	case canSplitH && canSplitV:
+		// prefer splitting along the longer axis so areas don't get too long and thin
+		if node.area.W > node.area.H*5/4 {
+			splitH = true
+		} else if node.area.H > node.area.W*5/4 {
+			splitH = false
+		} else {
+			splitH = rng.IntN(2) == 0
+		}
+	case canSplitH:
+		splitH = true
+	case canSplitV:
+		splitH = false
+	default:
+		return
+	}
+
+	a, b := node.area, node.area
+	if splitH {
+		a.W = randRange(rng, minSize, node.area.W-minSize)
+		b.X, b.W = a.X+a.W, node.area.W-a.W
+	} else {
+		a.H = randRange(rng, minSize, node.area.H-minSize)
+		b.Y, b.H = a.Y+a.H, node.area.H-a.H
+	}
+
+	node.left, node.right = &bspNode{area: a}, &bspNode{area: b}
+	g.split(rng, node.left, depth+1)
+	g.split(rng, node.right, depth+1)
+}
+
+func (g BSPGenerator) placeRooms(rng *rand.Rand, tm *rl.TileMap, node *bspNode, layout *Layout) {
+	if node.left != nil {
+		g.placeRooms(rng, tm, node.left, layout)
+		g.placeRooms(rng, tm, node.right, layout)
+		return
+	}
+
+	// leave a 1 tile gap on the right and bottom so rooms in neighbouring areas never touch
+	space := node.area
+	space.W, space.H = max(space.W-1, 1), max(space.H-1, 1)
+
+	minSize := g.minRoomSize()
+	room := vec.Rect{Dims: vec.Dims{randRange(rng, minSize, space.W), randRange(rng, minSize, space.H)}}
+	room.W, room.H = min(room.W, space.W), min(room.H, space.H) // area might be smaller than MinRoomSize
+	room.X = space.X + rng.IntN(space.W-room.W+1)
+	room.Y = space.Y + rng.IntN(space.H-room.H+1)
+
+	fill(tm, room, g.Floor)
+
+	region := Region{Bounds: room}
+	for pos := range room.EachCoord() {
+		region.Cells = append(region.Cells, pos)
+	}
+
+	node.room = len(layout.Regions)
+	layout.Regions = append(layout.Regions, region)
+}
+
+// joins the two halves of each node with a corridor between a room in each half, starting from the bottom of the tree.
+func (g BSPGenerator) connect(rng *rand.Rand, tm *rl.TileMap, node *bspNode, layout *Layout) {
+	if node.left == nil {
+		return
+	}
+
+	g.connect(rng, tm, node.left, layout)
+	g.connect(rng, tm, node.right, layout)
+
+	// find the closest pair of rooms between the two halves, so corridors don't cut across the whole map
+	a, b, best := 0, 0, -1
+	for _, roomA := range node.left.rooms() {
+		for _, roomB := range node.right.rooms() {
+			d := layout.Regions[roomA].Bounds.Center().DistanceSqTo(layout.Regions[roomB].Bounds.Center())
+			if best == -1 || d < best {
+				a, b, best = roomA, roomB, d
+			}
+		}
+	}
+
+	from := randomCoordIn(rng, layout.Regions[a].Bounds)
+	to := randomCoordIn(rng, layout.Regions[b].Bounds)
+	layout.linkCorridor(carveCorridor(tm, from, to, rng.IntN(2) == 0, g.Floor))
+}
+
+// returns the indices of all rooms in the subtree
+func (n *bspNode) rooms() []int {
+	if n.left == nil {
+		return []int{n.room}
+	}
+
+	return append(n.left.rooms(), n.right.rooms()...)
+}
@@ -0,0 +1,143 @@
+package gen
+
+import (
+	"math/rand/v2"
+
+	"github.com/bennicholls/tyumi/rl"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// CaveGenerator makes organic cave levels using cellular automata. The map starts as random noise, then is smoothed
+// over a number of iterations: walls with enough wall neighbours survive and open cells with enough wall neighbours
+// become walls. Each separate cave that results is a region in the Layout. Caves smaller than MinRegionSize are filled
+// in, and if Connect is true the remaining caves are joined by tunnels. Caves joined by a tunnel are linked in the
+// Layout, including any caves the tunnel cuts through.
+type CaveGenerator struct {
+	FillChance    float64 // chance that each cell starts as a wall. defaults to 0.45.
+	Iterations    int     // number of smoothing passes. defaults to 4.
+	BirthLimit    int     // open cells with at least this many wall neighbours become walls. defaults to 5.
+	SurvivalLimit int     // walls with at least this many wall neighbours stay walls. defaults to 4.
+	MinRegionSize int     // caves with fewer cells than this are filled in.
+	Connect       bool    // if true, joins all caves together with tunnels
+	Floor         rl.TileType
+	Wall          rl.TileType
+}
+
+func (g CaveGenerator) fillChance() float64 {
+	if g.FillChance <= 0 {
+		return 0.45
+	}
+
+	return g.FillChance
+}
+
+func (g CaveGenerator) iterations() int {
+	if g.Iterations <= 0 {
+		return 4
+	}
+
+	return g.Iterations
+}
+
+func (g CaveGenerator) birthLimit() int {
+	if g.BirthLimit <= 0 {
+		return 5
+	}
+
+	return g.BirthLimit
+}
+
+func (g CaveGenerator) survivalLimit() int {
+	if g.SurvivalLimit <= 0 {
+		return 4
+	}
+
+	return g.SurvivalLimit
+}
+
+// Generate fills the tilemap with caves. The edge of the map is always wall.
+func (g CaveGenerator) Generate(tm *rl.TileMap, seed uint64) (layout Layout) {
+	rng := newRNG(seed)
+	cave := newGrid(tm.Size())
+	interior := cave.Bounds().Contracted(1)
+	for pos := range interior.EachCoord() {
+		cave.set(pos, rng.Float64() >= g.fillChance())
+	}
+
+	for range g.iterations() {
+		cave = g.smooth(cave, interior)
+	}
+
+	for _, region := range cave.findRegions() {
+		if len(region.Cells) < g.MinRegionSize {
+			for _, cell := range region.Cells {
+				cave.set(cell, false)
+			}
+			continue
+		}
+
+		layout.Regions = append(layout.Regions, region)
+	}
+
+	cave.write(tm, g.Floor, g.Wall)
+
+	if g.Connect {
+		connectRegions(rng, tm, &layout, g.Floor)
+	}
+
+	return
+}
+
+func (g CaveGenerator) smooth(cave grid, interior vec.Rect) (smoothed grid) {
+	smoothed = newGrid(cave.size)
+	for pos := range interior.EachCoord() {
+		walls := 0
+		for _, dir := range vec.Directions {
+			if !cave.isOpen(pos.Step(dir)) {
+				walls++
+			}
+		}
+
+		if cave.isOpen(pos) {
+			smoothed.set(pos, walls < g.birthLimit())
+		} else {
+			smoothed.set(pos, walls < g.survivalLimit())
+		}
+	}
+
+	return
+}
+
+// connectRegions joins all regions in the layout with tunnels, building a spanning tree by repeatedly connecting the
+// closest unconnected region to the connected set.
+func connectRegions(rng *rand.Rand, tm *rl.TileMap, layout *Layout, floor rl.TileType) {
+	if len(layout.Regions) < 2 {
+		return
+	}
+
+	connected := []int{0}
+	unconnected := make([]int, 0, len(layout.Regions)-1)
+	for i := 1; i < len(layout.Regions); i++ {
+		unconnected = append(unconnected, i)
+	}
+
+	for len(unconnected) > 0 {
+		bestA, bestB, bestU, best := 0, 0, 0, -1
+		for _, a := range connected {
+			for u, b := range unconnected {
+				d := layout.Regions[a].Bounds.Center().DistanceSqTo(layout.Regions[b].Bounds.Center())
+				if best == -1 || d < best {
+					bestA, bestB, bestU, best = a, b, u, d
+				}
+			}
+		}
+
+		regionA, regionB := layout.Regions[bestA], layout.Regions[bestB]
+		from := regionA.Cells[rng.IntN(len(regionA.Cells))]
+		to := regionB.Cells[rng.IntN(len(regionB.Cells))]
+		layout.linkCorridor(carveCorridor(tm, from, to, rng.IntN(2) == 0, floor))
+
+		connected = append(connected, bestB)
+		unconnected = append(unconnected[:bestU], unconnected[bestU+1:]...)
+	}
+}
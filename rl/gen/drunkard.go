@@ -0,0 +1,66 @@
+package gen
+
+import (
+	"github.com/bennicholls/tyumi/rl"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// DrunkardsWalk carves winding, cave-like levels by sending walkers stumbling randomly around the map, opening up
+// every cell they step on. Walkers all start from the center of the map, so the result is always fully connected and
+// the Layout contains a single region.
+type DrunkardsWalk struct {
+	Coverage float64 // fraction of the map to open up before stopping. defaults to 0.4.
+	Walkers  int     // number of walkers to use, each taking an equal share of the work. defaults to 1.
+	MaxSteps int     // maximum steps each walker can take before giving up. if 0, defaults to 20 * the map's area.
+	Floor    rl.TileType
+	Wall     rl.TileType
+}
+
+func (g DrunkardsWalk) coverage() float64 {
+	if g.Coverage <= 0 {
+		return 0.4
+	}
+
+	return min(g.Coverage, 1)
+}
+
+// Generate fills the tilemap with walls and carves out the walkers' paths. The edge of the map is always wall.
+func (g DrunkardsWalk) Generate(tm *rl.TileMap, seed uint64) (layout Layout) {
+	rng := newRNG(seed)
+	cave := newGrid(tm.Size())
+	interior := cave.Bounds().Contracted(1)
+	if interior.Area() <= 0 {
+		cave.write(tm, g.Floor, g.Wall)
+		return
+	}
+
+	walkers := max(g.Walkers, 1)
+	target := int(float64(interior.Area()) * g.coverage())
+	maxSteps := g.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = 20 * interior.Area()
+	}
+
+	start := interior.Center()
+	cave.set(start, true)
+	open := 1
+	for w := range walkers {
+		// each walker opens up its share of the target before handing off to the next one
+		walkerTarget := target * (w + 1) / walkers
+		pos := start
+		for step := 0; step < maxSteps && open < walkerTarget; step++ {
+			if next := pos.Step(vec.CardinalDirections[rng.IntN(4)]); interior.Contains(next) {
+				pos = next
+				if !cave.isOpen(pos) {
+					cave.set(pos, true)
+					open++
+				}
+			}
+		}
+	}
+
+	cave.write(tm, g.Floor, g.Wall)
+	layout.Regions = cave.findRegions()
+
+	return
+}
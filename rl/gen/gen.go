@@ -0,0 +1,201 @@
+// Package gen provides procedural generators for building levels in an rl.TileMap: rooms and corridors, caves,
+// drunkard's walks, and prefabs stamped from REXPaint files. Generators write tiles using TileMap.SetTileType(), so run
+// them while the tilemap is being set up (before setting TileMap.Ready to true).
+//
+// Every generator takes a seed. Running a generator with the same seed, parameters, and tilemap size always produces
+// the same level. Generators also return a Layout describing the regions they made (rooms, caves, etc.) and how they
+// are connected, for placing stairs, monsters, loot and whatever else.
+package gen
+
+import (
+	"math/rand/v2"
+	"slices"
+
+	"github.com/bennicholls/tyumi/rl"
+	"github.com/bennicholls/tyumi/util"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// Region is an area of open space produced by a generator, like a room or a cave.
+type Region struct {
+	Bounds vec.Rect    // bounding rectangle of the region
+	Cells  []vec.Coord // every open position in the region
+}
+
+// Center returns the open cell of the region closest to the center of its bounds.
+func (r Region) Center() (center vec.Coord) {
+	target := r.Bounds.Center()
+	best := -1
+	for _, cell := range r.Cells {
+		if d := cell.DistanceSqTo(target); best == -1 || d < best {
+			best = d
+			center = cell
+		}
+	}
+
+	return
+}
+
+// Link is a connection between two regions in a Layout, referenced by their indices.
+type Link struct {
+	A, B int
+}
+
+// Layout describes the regions a generator made, and the links between them. Regions are linked if a corridor joins
+// them directly, so a corridor that cuts through other regions on its way links each region to the next one along it.
+type Layout struct {
+	Regions []Region
+	Links   []Link
+}
+
+// Neighbours returns the indices of the regions linked to the region at index i.
+func (l Layout) Neighbours(i int) (neighbours []int) {
+	for _, link := range l.Links {
+		if link.A == i {
+			neighbours = append(neighbours, link.B)
+		} else if link.B == i {
+			neighbours = append(neighbours, link.A)
+		}
+	}
+
+	return
+}
+
+// RegionAt returns the index of the region containing pos, or -1 if pos is not in any region.
+func (l Layout) RegionAt(pos vec.Coord) int {
+	for i, region := range l.Regions {
+		if region.Bounds.Contains(pos) && slices.Contains(region.Cells, pos) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (l *Layout) addLink(a, b int) {
+	if a == b || slices.Contains(l.Links, Link{a, b}) || slices.Contains(l.Links, Link{b, a}) {
+		return
+	}
+
+	l.Links = append(l.Links, Link{a, b})
+}
+
+// links the regions along a corridor, in the order the corridor passes through them.
+func (l *Layout) linkCorridor(cells []vec.Coord) {
+	prev := -1
+	for _, cell := range cells {
+		if region := l.RegionAt(cell); region != -1 {
+			if prev != -1 {
+				l.addLink(prev, region)
+			}
+			prev = region
+		}
+	}
+}
+
+func newRNG(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
+
+// returns a random int in [min, max]
+func randRange(rng *rand.Rand, min, max int) int {
+	if max <= min {
+		return min
+	}
+
+	return min + rng.IntN(max-min+1)
+}
+
+func randomCoordIn(rng *rand.Rand, area vec.Rect) vec.Coord {
+	return vec.Coord{area.X + rng.IntN(area.W), area.Y + rng.IntN(area.H)}
+}
+
+func fill(tm *rl.TileMap, area vec.Rect, tile_type rl.TileType) {
+	for pos := range area.EachCoord() {
+		tm.SetTileType(pos, tile_type)
+	}
+}
+
+// carves an L-shaped corridor from -> to. if horizontal_first, the corridor goes sideways first and then up/down.
+func carveCorridor(tm *rl.TileMap, from, to vec.Coord, horizontal_first bool, floor rl.TileType) (cells []vec.Coord) {
+	corner := vec.Coord{to.X, from.Y}
+	if !horizontal_first {
+		corner = vec.Coord{from.X, to.Y}
+	}
+
+	for _, line := range []vec.Line{{from, corner}, {corner, to}} {
+		for pos := range line.EachCoord() {
+			tm.SetTileType(pos, floor)
+			cells = append(cells, pos)
+		}
+	}
+
+	return
+}
+
+// grid is a simple open/closed map used by generators that need to work on the level before writing it to the
+// tilemap.
+type grid struct {
+	size vec.Dims
+	open []bool
+}
+
+func newGrid(size vec.Dims) grid {
+	return grid{size: size, open: make([]bool, size.Area())}
+}
+
+func (g grid) Bounds() vec.Rect {
+	return g.size.Bounds()
+}
+
+func (g grid) isOpen(pos vec.Coord) bool {
+	return g.Bounds().Contains(pos) && g.open[pos.ToIndex(g.size.W)]
+}
+
+func (g grid) set(pos vec.Coord, open bool) {
+	if g.Bounds().Contains(pos) {
+		g.open[pos.ToIndex(g.size.W)] = open
+	}
+}
+
+// finds all 4-way connected open regions in the grid.
+func (g grid) findRegions() (regions []Region) {
+	var visited util.Set[vec.Coord]
+	for pos := range g.Bounds().EachCoord() {
+		if !g.isOpen(pos) || visited.Contains(pos) {
+			continue
+		}
+
+		region := Region{Bounds: vec.Rect{pos, vec.Dims{1, 1}}}
+		queue := []vec.Coord{pos}
+		visited.Add(pos)
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			region.Cells = append(region.Cells, current)
+			region.Bounds = region.Bounds.CalcExtendedRect(current)
+
+			for _, dir := range vec.CardinalDirections {
+				if next := current.Step(dir); g.isOpen(next) && !visited.Contains(next) {
+					visited.Add(next)
+					queue = append(queue, next)
+				}
+			}
+		}
+
+		regions = append(regions, region)
+	}
+
+	return
+}
+
+// writes the grid to the tilemap, using floor for open cells and wall for closed ones.
+func (g grid) write(tm *rl.TileMap, floor, wall rl.TileType) {
+	for pos := range g.Bounds().EachCoord() {
+		if g.isOpen(pos) {
+			tm.SetTileType(pos, floor)
+		} else {
+			tm.SetTileType(pos, wall)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package gen
+
+import (
+	"github.com/bennicholls/tyumi/gfx"
+	"github.com/bennicholls/tyumi/log"
+	"github.com/bennicholls/tyumi/rl"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// Prefab is a hand-made chunk of level (a vault, a shrine, a boss room, etc.) that can be stamped into a tilemap.
+// Prefabs are drawn in REXPaint and loaded with LoadPrefab(), which uses a glyph map to decide which tile type each
+// glyph in the image represents.
+type Prefab struct {
+	size  vec.Dims
+	tiles []rl.TileType // TILE_NONE for cells that should not be stamped
+}
+
+// LoadPrefab loads a prefab from a REXPaint .xp file. Each drawn cell's glyph is looked up in the glyph map to find
+// its tile type. Undrawn cells, and cells with glyphs not in the map, are left alone when the prefab is stamped, so
+// prefabs don't have to be rectangular.
+func LoadPrefab(path string, glyph_map map[gfx.Glyph]rl.TileType) (prefab Prefab) {
+	image := gfx.ImportXPData(path)
+	if image.Size().Area() == 0 {
+		log.Error("Could not load prefab ", path)
+		return
+	}
+
+	prefab.size = image.Size()
+	prefab.tiles = make([]rl.TileType, prefab.size.Area())
+	for pos := range prefab.size.Bounds().EachCoord() {
+		cell := image.GetCell(pos)
+		if cell.Mode == gfx.DRAW_NONE {
+			continue
+		}
+
+		if tileType, ok := glyph_map[cell.Glyph]; ok {
+			prefab.tiles[pos.ToIndex(prefab.size.W)] = tileType
+		}
+	}
+
+	return
+}
+
+func (p Prefab) Size() vec.Dims {
+	return p.size
+}
+
+// Stamp writes the prefab's tiles to the tilemap with its top-left corner at pos. Parts of the prefab that fall
+// outside the tilemap are skipped. Returns a region covering the stamped tiles that are passable, which can be added to
+// a layout.
+func (p Prefab) Stamp(tm *rl.TileMap, pos vec.Coord) (region Region) {
+	for cursor := range p.size.Bounds().EachCoord() {
+		tileType := p.tiles[cursor.ToIndex(p.size.W)]
+		target := pos.Add(cursor)
+		if tileType == rl.TILE_NONE || !tm.Bounds().Contains(target) {
+			continue
+		}
+
+		tm.SetTileType(target, tileType)
+		if tileType.Data().Passable {
+			region.Bounds = region.Bounds.CalcExtendedRect(target)
+			region.Cells = append(region.Cells, target)
+		}
+	}
+
+	return
+}
+
+// Fits reports whether the prefab would fit entirely inside the area if stamped at pos.
+func (p Prefab) Fits(area vec.Rect, pos vec.Coord) bool {
+	farCorner := pos.Add(vec.Coord{p.size.W - 1, p.size.H - 1})
+	return p.size.Area() > 0 && area.Contains(pos) && area.Contains(farCorner)
+}
@@ -1,18 +1,37 @@
 package rl
 
 import (
+	"slices"
+
 	"github.com/bennicholls/tyumi/event"
 	"github.com/bennicholls/tyumi/rl/ecs"
 )
 
 func init() {
-	ecs.Register[HealthComponent]()
+	ecs.Register[HealthComponent]("rl.Health")
 }
 
+// HealthComponent gives an entity hit points, so it can be damaged, healed and killed. Damage is reduced by the
+// entity's resistances and armour, and then absorbed by its shield before it reaches its HP. See Damage() for details.
 type HealthComponent struct {
 	ecs.Component
 
-	HP Stat[int]
+	HP     Stat[int]
+	Shield Stat[int] // absorbs damage before it reaches HP
+	Armour int       // flat reduction to physical damage
+
+	// Resistances reduce damage of each type by a percentage. 100 means immune, negative values are weaknesses
+	// (-100 means double damage).
+	Resistances map[DamageType]int
+
+	Regen           int            // HP restored at the end of each of the entity's turns
+	HealingOverTime []HealOverTime // active healing effects, applied at the end of each of the entity's turns
+}
+
+// HealOverTime restores Amount HP at the end of each of the entity's turns, for the next Turns turns.
+type HealOverTime struct {
+	Amount int
+	Turns  int
 }
 
 func (hc *HealthComponent) ChangeHealth(delta int) {
@@ -36,3 +55,118 @@ func (hc *HealthComponent) ChangeHealth(delta int) {
 		}
 	}
 }
+
+// IsDead reports whether the entity has run out of HP.
+func (hc HealthComponent) IsDead() bool {
+	return hc.HP.Get() == 0
+}
+
+// Damage deals damage of the provided type to the entity, returning the amount of HP it lost. The damage is first
+// reduced by the entity's resistance to the type, then physical damage is reduced by the entity's armour, and whatever
+// is left is absorbed by the shield before it reaches the entity's HP. To have an entity attack another, use
+// ResolveAttack() instead, so the attack is reported to anyone listening.
+func (hc *HealthComponent) Damage(amount int, damage_type DamageType) (dealt int) {
+	if hc.IsDead() {
+		return 0
+	}
+
+	dealt, _ = hc.mitigate(amount, damage_type)
+	hc.ChangeHealth(-dealt)
+
+	return
+}
+
+// mitigate applies resistances, armour and shields to the damage, returning the damage that makes it through to the HP
+// and the amount that was blocked. Damage absorbed by the shield is taken out of the shield here.
+func (hc *HealthComponent) mitigate(amount int, damage_type DamageType) (damage, blocked int) {
+	if amount <= 0 {
+		return
+	}
+
+	damage = amount
+	if damage_type != DAMAGE_TRUE {
+		if resistance := min(hc.Resistances[damage_type], 100); resistance != 0 {
+			damage = damage * (100 - resistance) / 100
+		}
+
+		if damage_type == DAMAGE_PHYSICAL {
+			damage = max(damage-hc.Armour, 0)
+		}
+	}
+
+	damage = hc.Shield.Absorb(damage)
+	blocked = max(amount-damage, 0)
+	damage = min(damage, hc.HP.toMin())
+
+	return
+}
+
+// Heal restores HP to the entity, returning the amount actually restored. Dead entities can't be healed.
+func (hc *HealthComponent) Heal(amount int) (healed int) {
+	if hc.IsDead() || amount <= 0 {
+		return 0
+	}
+
+	oldHealth := hc.HP.Get()
+	hc.ChangeHealth(amount)
+
+	return hc.HP.Get() - oldHealth
+}
+
+// AddHealOverTime heals the entity by amount HP at the end of each of its next turns.
+func (hc *HealthComponent) AddHealOverTime(amount, turns int) {
+	if amount <= 0 || turns <= 0 {
+		return
+	}
+
+	hc.HealingOverTime = append(hc.HealingOverTime, HealOverTime{Amount: amount, Turns: turns})
+}
+
+// applies regeneration and healing over time. called at the end of the entity's turn.
+func (hc *HealthComponent) tick() {
+	heal := hc.Regen
+	for i := range hc.HealingOverTime {
+		heal += hc.HealingOverTime[i].Amount
+		hc.HealingOverTime[i].Turns -= 1
+	}
+
+	hc.HealingOverTime = slices.DeleteFunc(hc.HealingOverTime, func(hot HealOverTime) bool {
+		return hot.Turns <= 0
+	})
+
+	hc.Heal(heal)
+}
+
+// HealthSystem handles the passage of time and death for entities with a HealthComponent: regeneration and healing
+// over time are applied at the end of each actor's turn (see TurnSystem), and entities that die are removed from the
+// tilemap.
+type HealthSystem struct {
+	System
+
+	tileMap *TileMap
+}
+
+func (hs *HealthSystem) Init(tm *TileMap) {
+	hs.tileMap = tm
+	hs.Listen(EV_TURNENDED, EV_ENTITYDIED)
+	hs.SetImmediateEventHandler(hs.immediateHandleEvent)
+}
+
+func (hs *HealthSystem) immediateHandleEvent(e event.Event) (event_handled bool) {
+	switch e.ID() {
+	case EV_TURNENDED:
+		entity := e.(*EntityEvent).Entity
+		if health := ecs.Get[HealthComponent](entity); health != nil && hs.tileMap.containsEntity(entity) {
+			health.tick()
+		}
+	case EV_ENTITYDIED:
+		entity := e.(*EntityEvent).Entity
+		if hs.tileMap.containsEntity(entity) {
+			hs.tileMap.removeFromMap(entity)
+		}
+	default:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,116 @@
+package rl
+
+import (
+	"fmt"
+
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/gfx/ui"
+	"github.com/bennicholls/tyumi/rl/ecs"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// InventoryList is a list element that shows the contents of an entity's inventory, one item per line. It keeps
+// itself up to date as the inventory changes. Items can be selected and highlighted like in any other list; use
+// GetSelectedItem() to find out which item is selected.
+type InventoryList struct {
+	ui.List
+
+	LabelFunction func(item Entity) string // function used to make the label for each item. defaults to ItemLabel()
+
+	holder Entity
+	items  []Entity // items in the order they are displayed
+}
+
+func NewInventoryList(size vec.Dims, pos vec.Coord, depth int, holder Entity) (il *InventoryList) {
+	il = new(InventoryList)
+	il.Init(size, pos, depth, holder)
+
+	return
+}
+
+func (il *InventoryList) Init(size vec.Dims, pos vec.Coord, depth int, holder Entity) {
+	il.List.Init(size, pos, depth)
+	il.TreeNode.Init(il)
+	il.SetEmptyText("Nothing.")
+	il.EnableSelection()
+	il.EnableHighlight()
+
+	il.SetEventHandler(il.handleEvent)
+	il.Listen(EV_INVENTORYCHANGED)
+
+	il.SetHolder(holder)
+}
+
+func (il *InventoryList) handleEvent(e event.Event) (event_handled bool) {
+	switch e.ID() {
+	case EV_INVENTORYCHANGED:
+		if e.(*EntityEvent).Entity == il.holder {
+			il.Refresh()
+			return true
+		}
+	}
+
+	return
+}
+
+// elements only listen for events while in a window, so we might have missed some changes
+func (il *InventoryList) EnableListening() {
+	il.List.EnableListening()
+	il.Refresh()
+}
+
+// SetHolder changes the entity whose inventory is shown.
+func (il *InventoryList) SetHolder(holder Entity) {
+	il.holder = holder
+	il.Refresh()
+}
+
+func (il *InventoryList) GetHolder() Entity {
+	return il.holder
+}
+
+// Refresh rebuilds the list from the holder's inventory. This is done automatically when the inventory changes.
+func (il *InventoryList) Refresh() {
+	selection := il.GetSelectionIndex()
+	il.RemoveAll()
+	il.items = il.items[:0]
+
+	if !il.holder.IsValid() {
+		return
+	}
+
+	inventory := ecs.Get[InventoryComponent](il.holder)
+	if inventory == nil {
+		return
+	}
+
+	labelFunc := il.LabelFunction
+	if labelFunc == nil {
+		labelFunc = ItemLabel
+	}
+
+	for _, item := range inventory.Items {
+		il.items = append(il.items, item)
+		il.Insert(ui.NewTextbox(vec.Dims{il.Size().W, ui.FIT_TEXT}, vec.ZERO_COORD, 0, labelFunc(item), ui.ALIGN_LEFT))
+	}
+
+	il.Select(selection)
+}
+
+// GetSelectedItem returns the item currently selected in the list, or INVALID_ENTITY if the list is empty.
+func (il *InventoryList) GetSelectedItem() Entity {
+	if idx := il.GetSelectionIndex(); idx >= 0 && idx < len(il.items) {
+		return il.items[idx]
+	}
+
+	return INVALID_ENTITY
+}
+
+// ItemLabel returns the name of an item, along with the number of items if it is a stack of more than one.
+func ItemLabel(item Entity) string {
+	if itemComp := ecs.Get[ItemComponent](item); itemComp != nil && itemComp.Count > 1 {
+		return fmt.Sprintf("%s (x%d)", item.GetName(), itemComp.Count)
+	}
+
+	return item.GetName()
+}
@@ -0,0 +1,295 @@
+package rl
+
+import (
+	"slices"
+
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/rl/ecs"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+func init() {
+	ecs.Register[ItemComponent]("rl.Item")
+	ecs.Register[InventoryComponent]("rl.Inventory")
+}
+
+var (
+	EV_ITEMPICKEDUP     = event.Register("An entity picked up an item.")
+	EV_ITEMDROPPED      = event.Register("An entity dropped an item.")
+	EV_ITEMTRANSFERRED  = event.Register("An item was moved from one inventory to another.")
+	EV_INVENTORYCHANGED = event.Register("The contents of an inventory changed.")
+)
+
+// ItemEvent is fired when items are picked up, dropped, or transferred. For pickups and drops, Pos is where the item
+// was picked up from or dropped to. For transfers, From and To are the entities holding the inventories.
+type ItemEvent struct {
+	event.EventPrototype
+
+	Item     Entity
+	From, To Entity
+	Pos      vec.Coord
+}
+
+// ItemComponent is for entities that can be picked up and carried around in inventories. Items should usually be
+// passable entities (see EntityData.Passable) so they can lie around in tile piles.
+//
+// Stackable items of the same entity type are combined into a single entity when they go into an inventory, with the
+// Count tracking how many there are.
+type ItemComponent struct {
+	ecs.Component
+
+	Weight    int    // weight of a single item
+	Stackable bool   // whether items of this type can stack together
+	Count     int    // number of items in the stack. defaults to 1.
+	Holder    Entity // entity whose inventory holds this item, if any
+}
+
+func (ic *ItemComponent) Init() {
+	if ic.Count <= 0 {
+		ic.Count = 1
+	}
+}
+
+// items being destroyed are removed from the inventory holding them
+func (ic *ItemComponent) Cleanup() {
+	if !ic.Holder.IsValid() || !ecs.Alive(ic.Holder) {
+		return
+	}
+
+	if inventory := ecs.Get[InventoryComponent](ic.Holder); inventory != nil {
+		inventory.remove(Entity(ic.GetEntity()))
+	}
+}
+
+func (ic *ItemComponent) RemapEntities(remap ecs.EntityRemap) {
+	ic.Holder = Entity(remap.Remap(ecs.Entity(ic.Holder)))
+}
+
+// TotalWeight returns the weight of the whole stack.
+func (ic ItemComponent) TotalWeight() int {
+	return ic.Weight * ic.Count
+}
+
+// canStackWith reports whether the item can stack with another item.
+func canStackWith(item, other Entity) bool {
+	if item == other {
+		return false
+	}
+
+	itemComp, otherComp := ecs.Get[ItemComponent](item), ecs.Get[ItemComponent](other)
+	if itemComp == nil || otherComp == nil || !itemComp.Stackable || !otherComp.Stackable {
+		return false
+	}
+
+	return ecs.Get[EntityComponent](item).EntityType == ecs.Get[EntityComponent](other).EntityType
+}
+
+// SplitStack splits count items off of a stack, returning a new item entity holding them. The new entity is not in
+// the tilemap or any inventory. If count is not less than the size of the stack, the stack itself is returned
+// unchanged. Returns INVALID_ENTITY if the entity isn't an item or count is 0 or less.
+func SplitStack(item Entity, count int) Entity {
+	itemComp := ecs.Get[ItemComponent](item)
+	if itemComp == nil || count <= 0 {
+		return INVALID_ENTITY
+	}
+
+	if count >= itemComp.Count {
+		return item
+	}
+
+	split := Entity(ecs.CopyEntity(item))
+	itemComp = ecs.Get[ItemComponent](item) // copying may have moved the component
+	itemComp.Count -= count
+	splitComp := ecs.Get[ItemComponent](split)
+	splitComp.Count = count
+	splitComp.Holder = INVALID_ENTITY
+	if position := ecs.Get[PositionComponent](split); position != nil {
+		position.Coord = NOT_IN_TILEMAP
+	}
+
+	if itemComp.Holder.IsValid() {
+		event.Fire(EV_INVENTORYCHANGED, &EntityEvent{Entity: itemComp.Holder})
+	}
+
+	return split
+}
+
+// InventoryComponent is for entities that can carry items.
+type InventoryComponent struct {
+	ecs.Component
+
+	Capacity  int      // maximum number of items (stacks count as one). if 0, there is no limit.
+	MaxWeight int      // maximum total weight of items. if 0, there is no limit.
+	Items     []Entity // items in the inventory, in the order they were added
+}
+
+// anything left in an inventory is destroyed along with it. drop things first if you want them to stick around!
+func (ic *InventoryComponent) Cleanup() {
+	items := ic.Items
+	ic.Items = nil
+	for _, item := range items {
+		if ecs.Alive(item) {
+			ecs.Get[ItemComponent](item).Holder = INVALID_ENTITY
+			item.Destroy()
+		}
+	}
+}
+
+func (ic *InventoryComponent) RemapEntities(remap ecs.EntityRemap) {
+	for i, item := range ic.Items {
+		ic.Items[i] = Entity(remap.Remap(ecs.Entity(item)))
+	}
+	ic.Items = slices.DeleteFunc(ic.Items, func(e Entity) bool { return !e.IsValid() })
+}
+
+// Weight returns the total weight of everything in the inventory.
+func (ic InventoryComponent) Weight() (weight int) {
+	for _, item := range ic.Items {
+		weight += ecs.Get[ItemComponent](item).TotalWeight()
+	}
+
+	return
+}
+
+func (ic InventoryComponent) Contains(item Entity) bool {
+	return slices.Contains(ic.Items, item)
+}
+
+// CanHold reports whether the item would fit in the inventory, given its capacity and weight limits.
+func (ic InventoryComponent) CanHold(item Entity) bool {
+	itemComp := ecs.Get[ItemComponent](item)
+	if itemComp == nil {
+		return false
+	}
+
+	return ic.canHold(item, itemComp.Count)
+}
+
+// checks whether count items from the stack would fit in the inventory.
+func (ic InventoryComponent) canHold(item Entity, count int) bool {
+	itemComp := ecs.Get[ItemComponent](item)
+	if itemComp == nil || ic.Contains(item) {
+		return false
+	}
+
+	if ic.MaxWeight > 0 && ic.Weight()+itemComp.Weight*count > ic.MaxWeight {
+		return false
+	}
+
+	if ic.Capacity > 0 && len(ic.Items) >= ic.Capacity {
+		return slices.ContainsFunc(ic.Items, func(held Entity) bool { return canStackWith(item, held) })
+	}
+
+	return true
+}
+
+// adds the item, merging it into a stack if possible. returns the entity now holding the item, which will be
+// different if it was merged (and the added item destroyed).
+func (ic *InventoryComponent) add(item Entity) Entity {
+	holder := Entity(ic.GetEntity())
+	itemComp := ecs.Get[ItemComponent](item)
+	for _, held := range ic.Items {
+		if canStackWith(item, held) {
+			ecs.Get[ItemComponent](held).Count += itemComp.Count
+			itemComp.Holder = INVALID_ENTITY
+			item.Destroy()
+			event.Fire(EV_INVENTORYCHANGED, &EntityEvent{Entity: holder})
+			return held
+		}
+	}
+
+	ic.Items = append(ic.Items, item)
+	itemComp.Holder = holder
+	event.Fire(EV_INVENTORYCHANGED, &EntityEvent{Entity: holder})
+	return item
+}
+
+func (ic *InventoryComponent) remove(item Entity) bool {
+	idx := slices.Index(ic.Items, item)
+	if idx == -1 {
+		return false
+	}
+
+	ic.Items = slices.Delete(ic.Items, idx, idx+1)
+	ecs.Get[ItemComponent](item).Holder = INVALID_ENTITY
+	event.Fire(EV_INVENTORYCHANGED, &EntityEvent{Entity: Entity(ic.GetEntity())})
+	return true
+}
+
+// PickUpItem takes an item from the tilemap and puts it in the holder's inventory. Distance isn't checked, so make sure
+// the holder can actually reach the item first. Returns the item entity now in the inventory (stackable items are
+// merged into existing stacks, destroying the original entity), or INVALID_ENTITY if the item couldn't be picked up.
+func (tm *TileMap) PickUpItem(holder, item Entity) Entity {
+	inventory := ecs.Get[InventoryComponent](holder)
+	if inventory == nil || !item.IsInTilemap() || !inventory.CanHold(item) {
+		return INVALID_ENTITY
+	}
+
+	pos := item.Position()
+	tm.RemoveEntity(item)
+	held := inventory.add(item)
+	event.Fire(EV_ITEMPICKEDUP, &ItemEvent{Item: held, From: INVALID_ENTITY, To: holder, Pos: pos})
+
+	return held
+}
+
+// DropItem takes an item out of the holder's inventory and puts it in the tilemap at the holder's position. If count is
+// provided only that many items are split off of the stack and dropped. Returns the item entity that was dropped, or
+// INVALID_ENTITY if nothing could be dropped.
+func (tm *TileMap) DropItem(holder, item Entity, count ...int) Entity {
+	inventory := ecs.Get[InventoryComponent](holder)
+	if inventory == nil || !inventory.Contains(item) || !holder.IsInTilemap() {
+		return INVALID_ENTITY
+	}
+
+	dropped := item
+	if len(count) > 0 {
+		if dropped = SplitStack(item, count[0]); dropped == INVALID_ENTITY {
+			return INVALID_ENTITY
+		}
+	}
+
+	pos := holder.Position()
+	if dropped == item {
+		inventory.remove(item)
+	}
+
+	if !tm.AddEntity(dropped, pos) {
+		// no room, so put it back
+		inventory.add(dropped)
+		return INVALID_ENTITY
+	}
+
+	event.Fire(EV_ITEMDROPPED, &ItemEvent{Item: dropped, From: holder, To: INVALID_ENTITY, Pos: pos})
+
+	return dropped
+}
+
+// TransferItem moves an item from one entity's inventory to another's. If count is provided only that many items are
+// split off of the stack and moved. Returns the item entity now in the receiving inventory, or INVALID_ENTITY if the
+// transfer failed.
+func TransferItem(from, to, item Entity, count ...int) Entity {
+	fromInventory, toInventory := ecs.Get[InventoryComponent](from), ecs.Get[InventoryComponent](to)
+	if fromInventory == nil || toInventory == nil || !fromInventory.Contains(item) {
+		return INVALID_ENTITY
+	}
+
+	moving_count := ecs.Get[ItemComponent](item).Count
+	if len(count) > 0 {
+		moving_count = min(count[0], moving_count)
+	}
+
+	if from == to || moving_count <= 0 || !toInventory.canHold(item, moving_count) {
+		return INVALID_ENTITY
+	}
+
+	moving := SplitStack(item, moving_count)
+	if moving == item {
+		fromInventory.remove(item)
+	}
+
+	held := toInventory.add(moving)
+	event.Fire(EV_ITEMTRANSFERRED, &ItemEvent{Item: held, From: from, To: to, Pos: NOT_IN_TILEMAP})
+
+	return held
+}
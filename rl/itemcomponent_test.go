@@ -0,0 +1,161 @@
+package rl
+
+import (
+	"testing"
+
+	"github.com/bennicholls/tyumi/rl/ecs"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+var (
+	itemTestHolder = RegisterEntityType(EntityData{Name: "item_test_holder"})
+	itemTestArrow  = RegisterEntityType(EntityData{Name: "item_test_arrow", Passable: true, CreateFunction: func(e Entity) {
+		ecs.Add(e, ItemComponent{Weight: 1, Stackable: true})
+	}})
+	itemTestRock = RegisterEntityType(EntityData{Name: "item_test_rock", Passable: true, CreateFunction: func(e Entity) {
+		ecs.Add(e, ItemComponent{Weight: 4})
+	}})
+)
+
+func newItemTestMap(t *testing.T) (tm *TileMap, holder Entity) {
+	t.Helper()
+
+	tm = new(TileMap)
+	tm.Init(vec.Dims{4, 4}, saveTestFloor)
+	holder = CreateEntity(itemTestHolder)
+	ecs.Add(holder, InventoryComponent{})
+	tm.AddEntity(holder, vec.Coord{1, 1})
+
+	return
+}
+
+func TestItemStacking(t *testing.T) {
+	tm, holder := newItemTestMap(t)
+	inventory := func() *InventoryComponent { return ecs.Get[InventoryComponent](holder) }
+
+	first, second := CreateEntity(itemTestArrow), CreateEntity(itemTestArrow)
+	ecs.Get[ItemComponent](second).Count = 3
+	tm.AddEntity(first, vec.Coord{1, 1})
+	tm.AddEntity(second, vec.Coord{1, 1})
+
+	if held := tm.PickUpItem(holder, first); held != first {
+		t.Fatal("Could not pick up first arrow.")
+	}
+
+	if held := tm.PickUpItem(holder, second); held != first {
+		t.Error("Second arrow was not merged into the first stack.")
+	}
+
+	ecs.ProcessQueuedEntities()
+	if ecs.Alive(second) {
+		t.Error("Merged arrow was not destroyed.")
+	}
+
+	if len(inventory().Items) != 1 || ecs.Get[ItemComponent](first).Count != 4 {
+		t.Errorf("Stack loaded incorrectly: %d items, count %d", len(inventory().Items), ecs.Get[ItemComponent](first).Count)
+	}
+
+	if inventory().Weight() != 4 {
+		t.Errorf("Stack weighs %d, wanted 4", inventory().Weight())
+	}
+
+	rock, other_rock := CreateEntity(itemTestRock), CreateEntity(itemTestRock)
+	tm.AddEntity(rock, vec.Coord{1, 1})
+	tm.AddEntity(other_rock, vec.Coord{1, 1})
+	tm.PickUpItem(holder, rock)
+	tm.PickUpItem(holder, other_rock)
+
+	if len(inventory().Items) != 3 {
+		t.Errorf("Unstackable items stacked: %d items in inventory, wanted 3", len(inventory().Items))
+	}
+
+	tm.Cleanup()
+}
+
+func TestSplitStack(t *testing.T) {
+	tm, holder := newItemTestMap(t)
+
+	stack := CreateEntity(itemTestArrow)
+	ecs.Get[ItemComponent](stack).Count = 5
+	tm.AddEntity(stack, vec.Coord{1, 1})
+	tm.PickUpItem(holder, stack)
+
+	split := SplitStack(stack, 2)
+	if split == stack || split == INVALID_ENTITY {
+		t.Fatal("SplitStack did not create a new stack.")
+	}
+
+	if ecs.Get[ItemComponent](stack).Count != 3 || ecs.Get[ItemComponent](split).Count != 2 {
+		t.Errorf("Split counts wrong: %d and %d, wanted 3 and 2", ecs.Get[ItemComponent](stack).Count, ecs.Get[ItemComponent](split).Count)
+	}
+
+	if ecs.Get[ItemComponent](split).Holder.IsValid() || split.Position() != NOT_IN_TILEMAP {
+		t.Error("Split stack should not be held or in the tilemap.")
+	}
+
+	if ecs.Get[InventoryComponent](holder).Contains(split) {
+		t.Error("Split stack was put in the inventory.")
+	}
+
+	if SplitStack(stack, 3) != stack || ecs.Get[ItemComponent](stack).Count != 3 {
+		t.Error("Splitting off the whole stack should return the stack unchanged.")
+	}
+
+	if SplitStack(stack, 0) != INVALID_ENTITY {
+		t.Error("Splitting off 0 items should fail.")
+	}
+
+	if dropped := tm.DropItem(holder, stack, 1); dropped == stack || ecs.Get[ItemComponent](stack).Count != 2 || dropped.Position() != holder.Position() {
+		t.Error("Dropping part of a stack failed.")
+	}
+
+	ecs.DestroyEntity(split)
+	tm.Cleanup()
+}
+
+func TestInventoryLimits(t *testing.T) {
+	tm, holder := newItemTestMap(t)
+	inventory := ecs.Get[InventoryComponent](holder)
+	inventory.Capacity = 2
+	inventory.MaxWeight = 10
+
+	arrows, rock, other_rock := CreateEntity(itemTestArrow), CreateEntity(itemTestRock), CreateEntity(itemTestRock)
+	for _, item := range []Entity{arrows, rock, other_rock} {
+		tm.AddEntity(item, vec.Coord{1, 1})
+	}
+
+	tm.PickUpItem(holder, arrows)
+	tm.PickUpItem(holder, rock)
+
+	if tm.PickUpItem(holder, other_rock) != INVALID_ENTITY || other_rock.Position() != holder.Position() {
+		t.Error("Picked up an item past the inventory's capacity.")
+	}
+
+	// full, but arrows still stack
+	more_arrows := CreateEntity(itemTestArrow)
+	tm.AddEntity(more_arrows, vec.Coord{1, 1})
+	if tm.PickUpItem(holder, more_arrows) != arrows {
+		t.Error("Stackable item rejected from a full inventory.")
+	}
+
+	// 2 arrows + 1 rock = 6 weight, so 4 more arrows fit but 5 don't
+	heavy_arrows := CreateEntity(itemTestArrow)
+	ecs.Get[ItemComponent](heavy_arrows).Count = 5
+	tm.AddEntity(heavy_arrows, vec.Coord{1, 1})
+	if tm.PickUpItem(holder, heavy_arrows) != INVALID_ENTITY {
+		t.Error("Picked up items past the inventory's weight limit.")
+	}
+
+	other_holder := CreateEntity(itemTestHolder)
+	ecs.Add(other_holder, InventoryComponent{MaxWeight: 3})
+	if TransferItem(holder, other_holder, rock) != INVALID_ENTITY || !inventory.Contains(rock) {
+		t.Error("Transferred an item past the receiving inventory's weight limit.")
+	}
+
+	if moved := TransferItem(holder, other_holder, arrows, 1); moved == INVALID_ENTITY || ecs.Get[ItemComponent](arrows).Count != 1 {
+		t.Error("Could not transfer part of a stack.")
+	}
+
+	ecs.DestroyEntity(other_holder)
+	tm.Cleanup()
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/bennicholls/tyumi/anim"
 	"github.com/bennicholls/tyumi/event"
 	"github.com/bennicholls/tyumi/gfx"
+	"github.com/bennicholls/tyumi/gfx/col"
 	"github.com/bennicholls/tyumi/rl/ecs"
 	"github.com/bennicholls/tyumi/util"
 	"github.com/bennicholls/tyumi/vec"
@@ -16,20 +17,23 @@ var EV_LIGHTENABLED = event.Register("Light Enabled")
 var EV_LIGHTDISABLED = event.Register("Light Disabled")
 
 func init() {
-	ecs.Register[LightSourceComponent]()
+	ecs.Register[LightSourceComponent]("rl.LightSource")
 }
 
 type LightSourceComponent struct {
 	ecs.Component
 
 	Disabled     bool
-	Dirty        bool  // if true, light needs to be reapplied to its area
-	AreaDirty    bool  // if true, light needs to recompute which tiles it is affecting
-	Power        uint8 // light level applied at the source
-	FalloffRate  uint8 // amount light level diminishes every 1 tile away from source
-	MaxRange     uint8 // maximum range of the light. If 0 (default), the light's distance is computed from Power and FalloffRate
-	FlickerSpeed uint8 // How many ticks between flickers (at 60 ticks per second). If 0, flickering is disabled. NOTE: changing this after init does not update the flicker yet.
-	//Colour      col.Colour // Colour of light
+	Dirty        bool          // if true, light needs to be reapplied to its area
+	AreaDirty    bool          // if true, light needs to recompute which tiles it is affecting
+	Power        uint8         // light level applied at the source
+	FalloffRate  uint8         // amount light level diminishes every 1 tile away from source
+	MaxRange     uint8         // maximum range of the light. If 0 (default), the light's distance is computed from Power and FalloffRate
+	FlickerSpeed uint8         // How many ticks between flickers (at 60 ticks per second). If 0, flickering is disabled. NOTE: changing this after init does not update the flicker yet.
+	Colour       col.Colour    // Colour of light. If col.NONE (default), the light is white.
+	Facing       vec.Direction // Direction a directional light is pointing. Ignored unless Arc is set.
+	Arc          uint16        // Width of the light's beam in degrees, centered on Facing. If 0 (default) or 360+, the light shines in all directions.
+	FixedFacing  bool          // If true, the light's facing doesn't follow its entity when it moves or turns. Good for lighthouses.
 
 	photons   []photon // a photon is an amount of light being applied to a specific location on the tilemap
 	litbounds vec.Rect // rough bounding box containing all lit positions (and more, of course)
@@ -41,22 +45,37 @@ type LightSourceComponent struct {
 }
 
 type photon struct {
-	amount [4]uint8 // UP, RIGHT, DOWN, LEFT
-	pos    vec.Coord
+	amount       [4]uint8 // UP, RIGHT, DOWN, LEFT
+	colourAmount uint8    // amount of light used to tint the tile
+	colour       col.Colour
+	pos          vec.Coord
 }
 
-type tileLight [4]uint16 // UP, RIGHT, DOWN, LEFT
+type tileLight struct {
+	levels  [4]uint16 // UP, RIGHT, DOWN, LEFT
+	r, g, b uint32    // sums of each colour channel of the light hitting the tile, weighted by the amount of light
+	weight  uint32    // sum of the amounts of light used to weight the colour channels
+}
 
 func (tl tileLight) GetLevel(dir vec.Direction) uint16 {
-	return tl[dir/2]
+	return tl.levels[dir/2]
 }
 
 func (tl *tileLight) SetLevel(level uint8, dir vec.Direction) {
-	tl[dir/2] = uint16(level)
+	tl.levels[dir/2] = uint16(level)
 }
 
 func (tl tileLight) IsZero() bool {
-	return tl == tileLight{0, 0, 0, 0}
+	return tl.levels == [4]uint16{0, 0, 0, 0}
+}
+
+// adds (or removes, if negative) an amount of coloured light to the tile's colour
+func (tl *tileLight) addColour(colour col.Colour, amount int) {
+	r, g, b := colour.RGB()
+	tl.r = uint32(int(tl.r) + int(r)*amount)
+	tl.g = uint32(int(tl.g) + int(g)*amount)
+	tl.b = uint32(int(tl.b) + int(b)*amount)
+	tl.weight = uint32(int(tl.weight) + amount)
 }
 
 func (lsc *LightSourceComponent) Init() {
@@ -174,6 +193,55 @@ func (lsc *LightSourceComponent) SetMaxRange(max_range uint8) {
 	}
 }
 
+// SetColour changes the colour of the light. Use col.NONE for plain white light.
+func (lsc *LightSourceComponent) SetColour(colour col.Colour) {
+	if lsc.Colour == colour {
+		return
+	}
+
+	lsc.Colour = colour
+	if !lsc.Disabled {
+		lsc.Dirty = true
+	}
+}
+
+// SetFacing points a directional light in a new direction.
+func (lsc *LightSourceComponent) SetFacing(dir vec.Direction) {
+	if lsc.Facing == dir || dir == vec.DIR_NONE {
+		return
+	}
+
+	lsc.Facing = dir
+	if !lsc.Disabled && lsc.IsDirectional() {
+		lsc.AreaDirty = true
+	}
+}
+
+// SetArc sets the width of the light's beam in degrees. Use 0 to make the light shine in all directions.
+func (lsc *LightSourceComponent) SetArc(arc uint16) {
+	if lsc.Arc == arc {
+		return
+	}
+
+	lsc.Arc = arc
+	if !lsc.Disabled {
+		lsc.AreaDirty = true
+	}
+}
+
+// IsDirectional reports whether the light only shines in a cone, as opposed to all directions.
+func (lsc LightSourceComponent) IsDirectional() bool {
+	return lsc.Arc > 0 && lsc.Arc < 360
+}
+
+func (lsc LightSourceComponent) getColour() col.Colour {
+	if lsc.Colour == col.NONE {
+		return col.WHITE
+	}
+
+	return lsc.Colour
+}
+
 func (lsc *LightSourceComponent) GetMaxRange() (lightRange uint8) {
 	if lsc.MaxRange != 0 {
 		return lsc.MaxRange
@@ -217,7 +285,7 @@ func (ls *LightSystem) Init(tm *TileMap) {
 	ls.tileMap = tm
 	area := ls.tileMap.Bounds().Area()
 	ls.lightmap = make([]tileLight, area, area)
-	ls.Listen(EV_ENTITYMOVED, EV_TILECHANGEDVISIBILITY)
+	ls.Listen(EV_ENTITYMOVED, EV_ENTITYTURNED, EV_TILECHANGEDVISIBILITY)
 	ls.SetImmediateEventHandler(ls.immediateHandleEvent)
 	ls.Enable()
 }
@@ -245,7 +313,7 @@ func (ls LightSystem) GetLightLevel(pos, view_pos vec.Coord) uint8 {
 		if view_pos == NOT_IN_TILEMAP || view_pos == pos {
 			// if viewer is NOT IN TILEMAP we assume they are omniscient, and we light the tile
 			// with the largest light value among all directions
-			for _, level := range light {
+			for _, level := range light.levels {
 				viewedLight = max(viewedLight, level)
 			}
 		} else {
@@ -269,25 +337,25 @@ func (ls LightSystem) GetLightLevel(pos, view_pos vec.Coord) uint8 {
 				if viewDelta.X < 0 && viewDelta.Y < 0 {
 					if ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_UP)) && ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_LEFT)) {
 						if !ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_UPLEFT)) {
-							viewedLight = ls.getTileLight(pos.Step(vec.DIR_UPLEFT))[0]
+							viewedLight = ls.getTileLight(pos.Step(vec.DIR_UPLEFT)).levels[0]
 						}
 					}
 				} else if viewDelta.X < 0 && viewDelta.Y > 0 {
 					if ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_DOWN)) && ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_LEFT)) {
 						if !ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_DOWNLEFT)) {
-							viewedLight = ls.getTileLight(pos.Step(vec.DIR_DOWNLEFT))[0]
+							viewedLight = ls.getTileLight(pos.Step(vec.DIR_DOWNLEFT)).levels[0]
 						}
 					}
 				} else if viewDelta.X > 0 && viewDelta.Y < 0 {
 					if ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_UP)) && ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_RIGHT)) {
 						if !ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_UPRIGHT)) {
-							viewedLight = ls.getTileLight(pos.Step(vec.DIR_UPRIGHT))[0]
+							viewedLight = ls.getTileLight(pos.Step(vec.DIR_UPRIGHT)).levels[0]
 						}
 					}
 				} else if viewDelta.X > 0 && viewDelta.Y > 0 {
 					if ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_DOWN)) && ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_RIGHT)) {
 						if !ls.tileMap.IsTileOpaque(pos.Step(vec.DIR_DOWNRIGHT)) {
-							viewedLight = ls.getTileLight(pos.Step(vec.DIR_DOWNRIGHT))[0]
+							viewedLight = ls.getTileLight(pos.Step(vec.DIR_DOWNRIGHT)).levels[0]
 						}
 					}
 				}
@@ -303,7 +371,7 @@ func (ls LightSystem) GetLightLevel(pos, view_pos vec.Coord) uint8 {
 
 func (ls LightSystem) getTileLight(pos vec.Coord) *tileLight {
 	if !ls.Enabled {
-		return &tileLight{255, 255, 255, 255}
+		return &tileLight{levels: [4]uint16{255, 255, 255, 255}}
 	}
 
 	return &ls.lightmap[pos.ToIndex(ls.tileMap.size.W)]
@@ -317,16 +385,30 @@ func (ls *LightSystem) immediateHandleEvent(e event.Event) (event_handled bool)
 			light.AreaDirty = true
 			if !light.Disabled {
 				ls.sources.Remove(moveEvent.From)
-				ls.sources.Add(moveEvent.To)
+				if moveEvent.To.IsInside(ls.tileMap) {
+					ls.sources.Add(moveEvent.To)
+				}
+			}
+
+			// directional lights turn to face the way their entity is moving
+			if !light.FixedFacing && moveEvent.From != NOT_IN_TILEMAP && moveEvent.To != NOT_IN_TILEMAP {
+				light.SetFacing(moveEvent.From.DirectionTo(moveEvent.To))
 			}
 		}
+	case EV_ENTITYTURNED:
+		turnEvent := e.(*EntityTurnedEvent)
+		if light := ecs.Get[LightSourceComponent](turnEvent.Entity); light != nil && !light.FixedFacing {
+			light.SetFacing(turnEvent.Facing)
+		}
 	case EV_TILECHANGEDVISIBILITY:
 		visEvent := e.(*TileChangedVisibilityEvent)
 		ls.changedVisbilityTiles.Add(visEvent.Pos)
 	case EV_LIGHTENABLED:
 		lightEvent := e.(*EntityEvent)
 		ecs.Get[LightSourceComponent](lightEvent.Entity).AreaDirty = true
-		ls.sources.Add(lightEvent.Entity.Position())
+		if pos := lightEvent.Entity.Position(); pos.IsInside(ls.tileMap) {
+			ls.sources.Add(pos)
+		}
 	case EV_LIGHTDISABLED:
 		lightEvent := e.(*EntityEvent)
 		ls.sources.Remove(lightEvent.Entity.Position())
@@ -375,8 +457,30 @@ func (ls *LightSystem) Update(delta time.Duration) {
 	}
 }
 
-// LightTileVisuals applies the light level at the position to the computed tile visuals.
-func (ls *LightSystem) LightTileVisuals(vis gfx.Visuals, light_level uint8) (lit_vis gfx.Visuals) {
+// GetLightColour returns the colour of the light at the position: the average colour of all the lights hitting the
+// tile, weighted by how much light each one contributes. Global light counts as white light.
+func (ls LightSystem) GetLightColour(pos vec.Coord) col.Colour {
+	if !ls.Enabled {
+		return col.WHITE
+	}
+
+	light := ls.getTileLight(pos)
+	global := uint32(ls.globalLight)
+	weight := light.weight + global
+	if weight == 0 {
+		return col.WHITE
+	}
+
+	r := (light.r + 255*global) / weight
+	g := (light.g + 255*global) / weight
+	b := (light.b + 255*global) / weight
+
+	return col.MakeOpaque(uint8(r), uint8(g), uint8(b))
+}
+
+// LightTileVisuals applies the light level and colour at the position to the computed tile visuals. The foreground
+// colour is tinted by the colour of the light, and then faded toward the background colour as the light level drops.
+func (ls *LightSystem) LightTileVisuals(vis gfx.Visuals, light_level uint8, light_colour col.Colour) (lit_vis gfx.Visuals) {
 	if !ls.Enabled {
 		return vis
 	}
@@ -384,7 +488,11 @@ func (ls *LightSystem) LightTileVisuals(vis gfx.Visuals, light_level uint8) (lit
 	// TODO: this lighting function will act pretty weird if the backcolour is a light colour (like if something
 	// inverts the tile colours) should probably do this better somehow....
 	lit_vis = vis
-	lit_vis.Colours.Fore = vis.Colours.Back.Lerp(vis.Colours.Fore, int(light_level), 255)
+	fore := vis.Colours.Fore
+	if light_colour != col.WHITE {
+		fore = col.Blend(fore, light_colour, col.BLEND_MULTIPLY)
+	}
+	lit_vis.Colours.Fore = vis.Colours.Back.Lerp(fore, int(light_level), 255)
 
 	return
 }
@@ -401,9 +509,11 @@ func (ls *LightSystem) removeAppliedLight(light *LightSourceComponent) {
 		tileLight := ls.getTileLight(pos)
 
 		for i, value := range photon.amount {
-			tileLight[i] -= uint16(value)
+			tileLight.levels[i] -= uint16(value)
 		}
+		tileLight.addColour(photon.colour, -int(photon.colourAmount))
 		light.photons[idx].amount = zeroLight
+		light.photons[idx].colourAmount = 0
 		ls.tileMap.SetDirty(pos)
 	}
 }
@@ -432,8 +542,8 @@ func (ls *LightSystem) computeLightArea(light *LightSourceComponent) {
 	}
 
 	lightRange := light.GetMaxRange()
-	ls.tileMap.ShadowCast(source, int(lightRange), func(tm *TileMap, pos vec.Coord, d, r int) {
-		light.photons = append(light.photons, photon{zeroLight, pos})
+	ls.tileMap.ShadowCastCone(source, int(lightRange), light.Facing, int(light.Arc), func(tm *TileMap, pos vec.Coord, d, r int) {
+		light.photons = append(light.photons, photon{amount: zeroLight, pos: pos})
 		light.litbounds = light.litbounds.CalcExtendedRect(pos)
 	})
 
@@ -452,6 +562,7 @@ func (ls *LightSystem) applyLight(light *LightSourceComponent) {
 		return
 	}
 
+	colour := light.getColour()
 	for idx, photon := range light.photons {
 		pos := photon.pos
 		amplitude := uint8(max(0, int(light.Power)-int(source.DistanceTo(pos)*float64(light.FalloffRate))))
@@ -478,25 +589,33 @@ func (ls *LightSystem) applyLight(light *LightSourceComponent) {
 				newLight.SetLevel(amplitude/2, vec.DIR_UP)
 			}
 		} else {
-			newLight = tileLight{uint16(amplitude), uint16(amplitude), uint16(amplitude), uint16(amplitude)}
+			newLight.levels = [4]uint16{uint16(amplitude), uint16(amplitude), uint16(amplitude), uint16(amplitude)}
 		}
 
 		tileLight := ls.getTileLight(pos)
 
-		for i := range newLight {
-			if photon.amount[i] == uint8(newLight[i]) {
+		for i := range newLight.levels {
+			if photon.amount[i] == uint8(newLight.levels[i]) {
 				continue
 			}
 
-			delta := int(newLight[i]) - int(photon.amount[i])
+			delta := int(newLight.levels[i]) - int(photon.amount[i])
 			if delta > 0 {
-				tileLight[i] += uint16(delta)
+				tileLight.levels[i] += uint16(delta)
 			} else if delta < 0 {
-				tileLight[i] -= uint16(-delta)
+				tileLight.levels[i] -= uint16(-delta)
 			}
 
-			light.photons[idx].amount[i] = uint8(newLight[i])
+			light.photons[idx].amount[i] = uint8(newLight.levels[i])
+
+			ls.tileMap.SetDirty(pos)
+		}
 
+		if photon.colourAmount != amplitude || photon.colour != colour {
+			tileLight.addColour(photon.colour, -int(photon.colourAmount))
+			tileLight.addColour(colour, int(amplitude))
+			light.photons[idx].colourAmount = amplitude
+			light.photons[idx].colour = colour
 			ls.tileMap.SetDirty(pos)
 		}
 	}
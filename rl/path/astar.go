@@ -0,0 +1,98 @@
+package path
+
+import (
+	"container/heap"
+	"slices"
+
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// FindPath finds the cheapest path from start to goal using A*. The returned path does not include start, and ends at
+// goal. The goal is always considered enterable, even if it is blocked, so you can find a path to a tile holding
+// another entity (to attack it, for example); check the cost of the final step yourself if that matters. If no path
+// can be found, returns nil and ok = false.
+func (g Graph) FindPath(start, goal vec.Coord) (path []vec.Coord, ok bool) {
+	if !g.Area.Contains(start) || !g.Area.Contains(goal) {
+		return nil, false
+	}
+
+	if start == goal {
+		return []vec.Coord{}, true
+	}
+
+	costs := make(map[vec.Coord]int)
+	cameFrom := make(map[vec.Coord]vec.Coord)
+	costs[start] = 0
+
+	open := nodeQueue{{pos: start, priority: g.Movement.distance(start, goal)}}
+	for open.Len() > 0 {
+		current := heap.Pop(&open).(node)
+		if current.pos == goal {
+			for pos := goal; pos != start; pos = cameFrom[pos] {
+				path = append(path, pos)
+			}
+			slices.Reverse(path)
+
+			return path, true
+		}
+
+		// skip stale queue entries for positions we've since found a cheaper way to
+		if current.priority-g.Movement.distance(current.pos, goal) > costs[current.pos] {
+			continue
+		}
+
+		for _, dir := range g.Movement.Directions() {
+			next := current.pos.Step(dir)
+			stepCost := g.cost(next)
+			if next == goal && stepCost == IMPASSABLE {
+				stepCost = 1
+			}
+
+			if stepCost == IMPASSABLE {
+				continue
+			}
+
+			newCost := costs[current.pos] + stepCost
+			if oldCost, seen := costs[next]; seen && oldCost <= newCost {
+				continue
+			}
+
+			costs[next] = newCost
+			cameFrom[next] = current.pos
+			heap.Push(&open, node{pos: next, priority: newCost + g.Movement.distance(next, goal)})
+		}
+	}
+
+	return nil, false
+}
+
+type node struct {
+	pos      vec.Coord
+	priority int
+}
+
+// nodeQueue is a min-heap of nodes, for use with container/heap.
+type nodeQueue []node
+
+func (nq nodeQueue) Len() int {
+	return len(nq)
+}
+
+func (nq nodeQueue) Less(i, j int) bool {
+	return nq[i].priority < nq[j].priority
+}
+
+func (nq nodeQueue) Swap(i, j int) {
+	nq[i], nq[j] = nq[j], nq[i]
+}
+
+func (nq *nodeQueue) Push(x any) {
+	*nq = append(*nq, x.(node))
+}
+
+func (nq *nodeQueue) Pop() any {
+	old := *nq
+	n := old[len(old)-1]
+	*nq = old[:len(old)-1]
+	return n
+}
@@ -0,0 +1,273 @@
+package path
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/rl"
+	"github.com/bennicholls/tyumi/util"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// UNREACHABLE is the value of positions in a Dijkstra map that cannot reach any goal.
+const UNREACHABLE int = math.MaxInt
+
+// DijkstraMap holds, for every position in a graph, the cost of the cheapest path to the nearest of a set of goals.
+// Like FindPath(), goals can always be stepped onto even if they are blocked.
+// Movers can then find their way to a goal by always stepping downhill (see Direction() and Next()), which makes this
+// a flow field: one map can guide any number of movers, which is much cheaper than running A* for each of them.
+//
+// The map is built the first time it is queried. If the costs in the graph change, tell the map which positions have
+// changed with MarkChanged() and only the affected part of the map will be rebuilt. For maps over a tilemap, use
+// ListenForTileChanges() to have this done automatically.
+type DijkstraMap struct {
+	event.Stream
+
+	graph   Graph
+	goals   []vec.Coord
+	values  []int
+	costs   []int // costs of each position as of the last update, so we can tell how they've changed
+	changed util.Set[vec.Coord]
+	built   bool
+}
+
+// NewDijkstraMap creates a Dijkstra map over the graph, leading to the provided goals.
+func NewDijkstraMap(graph Graph, goals ...vec.Coord) (dm *DijkstraMap) {
+	dm = new(DijkstraMap)
+	dm.graph = graph
+	dm.values = make([]int, graph.Area.Area())
+	dm.costs = make([]int, graph.Area.Area())
+	dm.SetGoals(goals...)
+
+	return
+}
+
+// SetGoals replaces the map's goals. The map will be rebuilt from scratch the next time it is queried.
+func (dm *DijkstraMap) SetGoals(goals ...vec.Coord) {
+	dm.goals = dm.goals[:0]
+	for _, goal := range goals {
+		if dm.graph.Area.Contains(goal) {
+			dm.goals = append(dm.goals, goal)
+		}
+	}
+
+	dm.built = false
+}
+
+// Rebuild recomputes the whole map. You don't normally have to call this, since the map rebuilds itself as needed.
+func (dm *DijkstraMap) Rebuild() {
+	for pos := range dm.graph.Area.EachCoord() {
+		idx := dm.graph.index(pos)
+		dm.values[idx] = UNREACHABLE
+		dm.costs[idx] = dm.graph.cost(pos)
+	}
+
+	var queue nodeQueue
+	for _, goal := range dm.goals {
+		dm.values[dm.graph.index(goal)] = 0
+		queue = append(queue, node{pos: goal})
+	}
+
+	dm.propagate(queue)
+	dm.changed.RemoveAll()
+	dm.built = true
+}
+
+// MarkChanged tells the map that the cost of moving onto the provided positions may have changed. The affected parts
+// of the map will be recomputed the next time it is queried.
+func (dm *DijkstraMap) MarkChanged(positions ...vec.Coord) {
+	for _, pos := range positions {
+		if dm.graph.Area.Contains(pos) {
+			dm.changed.Add(pos)
+		}
+	}
+}
+
+// ListenForTileChanges has the map listen for changes to tiles (and entities moving around, for maps using
+// TileCost()), marking the changed positions automatically. Use DisableListening() to stop.
+func (dm *DijkstraMap) ListenForTileChanges() {
+	dm.Listen(rl.EV_TILECHANGED, rl.EV_TILECHANGEDVISIBILITY, rl.EV_ENTITYMOVED)
+	dm.SetImmediateEventHandler(dm.handleEvent)
+}
+
+func (dm *DijkstraMap) handleEvent(e event.Event) (event_handled bool) {
+	switch e.ID() {
+	case rl.EV_TILECHANGED:
+		dm.MarkChanged(e.(*rl.TileChangedEvent).Pos)
+	case rl.EV_TILECHANGEDVISIBILITY:
+		dm.MarkChanged(e.(*rl.TileChangedVisibilityEvent).Pos)
+	case rl.EV_ENTITYMOVED:
+		moveEvent := e.(*rl.EntityMovedEvent)
+		dm.MarkChanged(moveEvent.From, moveEvent.To)
+	default:
+		return
+	}
+
+	return true
+}
+
+// Value returns the cost of the cheapest path from pos to the nearest goal, or UNREACHABLE if there isn't one.
+func (dm *DijkstraMap) Value(pos vec.Coord) int {
+	if !dm.graph.Area.Contains(pos) {
+		return UNREACHABLE
+	}
+
+	dm.update()
+
+	return dm.values[dm.graph.index(pos)]
+}
+
+// Direction returns the direction to step from pos to move towards the nearest goal. Returns DIR_NONE if pos is a goal
+// or no goal can be reached from pos.
+func (dm *DijkstraMap) Direction(pos vec.Coord) (dir vec.Direction) {
+	dir = vec.DIR_NONE
+	best := dm.Value(pos)
+	if best == UNREACHABLE {
+		return
+	}
+
+	for _, d := range dm.graph.Movement.Directions() {
+		if value := dm.Value(pos.Step(d)); value < best {
+			best, dir = value, d
+		}
+	}
+
+	return
+}
+
+// Next returns the position to step to from pos to move towards the nearest goal. If pos is a goal or no goal can be
+// reached, returns ok = false.
+func (dm *DijkstraMap) Next(pos vec.Coord) (next vec.Coord, ok bool) {
+	dir := dm.Direction(pos)
+	if dir == vec.DIR_NONE {
+		return pos, false
+	}
+
+	return pos.Step(dir), true
+}
+
+// brings the map up to date, rebuilding it or applying any changes as necessary.
+func (dm *DijkstraMap) update() {
+	if !dm.built {
+		dm.Rebuild()
+		return
+	}
+
+	for pos := range dm.changed.EachElement() {
+		dm.updatePosition(pos)
+	}
+
+	dm.changed.RemoveAll()
+}
+
+// cost of stepping onto the position at idx, or IMPASSABLE. goals can always be stepped onto.
+func (dm *DijkstraMap) enterCost(idx int) int {
+	if dm.costs[idx] == IMPASSABLE && dm.values[idx] == 0 {
+		return 1
+	}
+
+	return dm.costs[idx]
+}
+
+// updates the map after the cost of stepping onto pos changes. if the cost went down, paths can only get cheaper so
+// we just propagate outwards from pos. if it went up, we find every position whose value might have depended on
+// stepping through pos, reset them, and refill them from their neighbours.
+func (dm *DijkstraMap) updatePosition(pos vec.Coord) {
+	idx := dm.graph.index(pos)
+	oldCost := dm.enterCost(idx)
+	dm.costs[idx] = dm.graph.cost(pos)
+	newCost := dm.enterCost(idx)
+	if oldCost == newCost {
+		return
+	}
+
+	if newCost != IMPASSABLE && (oldCost == IMPASSABLE || newCost < oldCost) {
+		if dm.values[idx] != UNREACHABLE {
+			dm.propagate(nodeQueue{{pos: pos, priority: dm.values[idx]}})
+		}
+
+		return
+	}
+
+	// find the region that may depend on pos. positions depend on a neighbour if their value is the neighbour's
+	// value plus the cost of stepping onto it.
+	var region []vec.Coord
+	var inRegion util.Set[vec.Coord]
+	addDependents := func(from vec.Coord, from_cost int) {
+		fromValue := dm.values[dm.graph.index(from)]
+		if fromValue == UNREACHABLE || from_cost == IMPASSABLE {
+			return
+		}
+
+		for _, dir := range dm.graph.Movement.Directions() {
+			neighbour := from.Step(dir)
+			if !dm.graph.Area.Contains(neighbour) || inRegion.Contains(neighbour) {
+				continue
+			}
+
+			if value := dm.values[dm.graph.index(neighbour)]; value != 0 && value == fromValue+from_cost {
+				region = append(region, neighbour)
+				inRegion.Add(neighbour)
+			}
+		}
+	}
+
+	addDependents(pos, oldCost)
+	for i := 0; i < len(region); i++ {
+		addDependents(region[i], dm.enterCost(dm.graph.index(region[i])))
+	}
+
+	// reset the region, then refill it from the positions bordering it
+	for _, p := range region {
+		dm.values[dm.graph.index(p)] = UNREACHABLE
+	}
+
+	var queue nodeQueue
+	for _, p := range region {
+		for _, dir := range dm.graph.Movement.Directions() {
+			neighbour := p.Step(dir)
+			if !dm.graph.Area.Contains(neighbour) || inRegion.Contains(neighbour) {
+				continue
+			}
+
+			if value := dm.values[dm.graph.index(neighbour)]; value != UNREACHABLE {
+				queue = append(queue, node{pos: neighbour, priority: value})
+			}
+		}
+	}
+
+	dm.propagate(queue)
+}
+
+// runs dijkstra's algorithm outwards from the queued positions, lowering the values of any positions that can be
+// reached more cheaply. impassable positions are given values (so a mover standing somewhere blocked, like on its own
+// tile, can still find its way) but paths are never propagated through them.
+func (dm *DijkstraMap) propagate(queue nodeQueue) {
+	heap.Init(&queue)
+	for queue.Len() > 0 {
+		current := heap.Pop(&queue).(node)
+		idx := dm.graph.index(current.pos)
+		if current.priority > dm.values[idx] {
+			continue // stale entry
+		}
+
+		stepCost := dm.enterCost(idx)
+		if stepCost == IMPASSABLE {
+			continue
+		}
+
+		for _, dir := range dm.graph.Movement.Directions() {
+			neighbour := current.pos.Step(dir)
+			if !dm.graph.Area.Contains(neighbour) {
+				continue
+			}
+
+			nIdx := dm.graph.index(neighbour)
+			if newValue := current.priority + stepCost; newValue < dm.values[nIdx] {
+				dm.values[nIdx] = newValue
+				heap.Push(&queue, node{pos: neighbour, priority: newValue})
+			}
+		}
+	}
+}
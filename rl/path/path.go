@@ -0,0 +1,110 @@
+// Package path provides pathfinding over grids: A* for finding a path between two points, and Dijkstra maps for
+// pathing many movers towards (or away from) a set of goals. Grids are described by a Graph, which can be made
+// from an rl.TileMap using TileCost() or TerrainCost(), or from any cost function you like.
+package path
+
+import (
+	"github.com/bennicholls/tyumi/rl"
+	"github.com/bennicholls/tyumi/util"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// Movement describes which directions movers are allowed to step in.
+type Movement int
+
+const (
+	MOVE_4WAY Movement = iota // cardinal directions only
+	MOVE_8WAY                 // cardinal and diagonal directions
+)
+
+// Directions returns the directions a mover can step in.
+func (m Movement) Directions() []vec.Direction {
+	if m == MOVE_8WAY {
+		return vec.Directions
+	}
+
+	return vec.CardinalDirections
+}
+
+// distance between two coords for this kind of movement, assuming every step costs 1. Used as the A* heuristic.
+func (m Movement) distance(from, to vec.Coord) int {
+	dx, dy := util.Abs(to.X-from.X), util.Abs(to.Y-from.Y)
+	if m == MOVE_8WAY {
+		return max(dx, dy)
+	}
+
+	return dx + dy
+}
+
+// IMPASSABLE is returned by cost functions for positions that cannot be moved onto.
+const IMPASSABLE int = -1
+
+// CostFunc reports the cost of moving onto the position pos. Return IMPASSABLE (or any negative number) if pos cannot
+// be moved onto. Costs of 0 are treated as 1.
+type CostFunc func(pos vec.Coord) int
+
+// Graph describes a grid to find paths over.
+type Graph struct {
+	Area     vec.Rect // the area that can be pathed over
+	Movement Movement
+	Cost     CostFunc // if nil, every position in the area costs 1 to move onto
+}
+
+// NewTileMapGraph makes a graph for pathing over a tilemap, using the provided cost function. Use TileCost() or
+// TerrainCost() for the usual tilemap rules, or write your own.
+func NewTileMapGraph(tilemap *rl.TileMap, movement Movement, cost CostFunc) Graph {
+	return Graph{
+		Area:     tilemap.Bounds(),
+		Movement: movement,
+		Cost:     cost,
+	}
+}
+
+// cost of moving onto pos, or IMPASSABLE if pos is out of bounds or blocked.
+func (g Graph) cost(pos vec.Coord) int {
+	if !g.Area.Contains(pos) {
+		return IMPASSABLE
+	}
+
+	if g.Cost == nil {
+		return 1
+	}
+
+	cost := g.Cost(pos)
+	if cost < 0 {
+		return IMPASSABLE
+	}
+
+	return max(cost, 1)
+}
+
+func (g Graph) index(pos vec.Coord) int {
+	return pos.Subtract(g.Area.Coord).ToIndex(g.Area.W)
+}
+
+// TileCost returns a cost function for the tilemap that blocks any tile that is not passable according to
+// Tile.IsPassable(), so tiles holding an entity are blocked as well as impassable terrain. Passable tiles cost their
+// tile type's MoveCost.
+func TileCost(tilemap *rl.TileMap) CostFunc {
+	return func(pos vec.Coord) int {
+		tile := tilemap.GetTile(pos)
+		if !tile.IsPassable() {
+			return IMPASSABLE
+		}
+
+		return tile.GetTileType().Data().MoveCost
+	}
+}
+
+// TerrainCost returns a cost function for the tilemap that only considers terrain: entities standing on tiles are
+// ignored. Good for Dijkstra maps, since entities move around a lot and would otherwise cause constant rebuilds.
+func TerrainCost(tilemap *rl.TileMap) CostFunc {
+	return func(pos vec.Coord) int {
+		data := tilemap.GetTile(pos).GetTileType().Data()
+		if !data.Passable {
+			return IMPASSABLE
+		}
+
+		return data.MoveCost
+	}
+}
@@ -0,0 +1,204 @@
+package path
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// makes a graph from rows of text: # is impassable, digits are costs, anything else costs 1. returns the costs so
+// tests can change them.
+func gridGraph(rows []string, movement Movement) (graph Graph, costs []int) {
+	area := vec.Rect{vec.ZERO_COORD, vec.Dims{len(rows[0]), len(rows)}}
+	costs = make([]int, 0, area.Area())
+	for _, row := range rows {
+		for _, c := range row {
+			switch {
+			case c == '#':
+				costs = append(costs, IMPASSABLE)
+			case c >= '0' && c <= '9':
+				costs = append(costs, int(c-'0'))
+			default:
+				costs = append(costs, 1)
+			}
+		}
+	}
+
+	graph = Graph{
+		Area:     area,
+		Movement: movement,
+		Cost:     func(pos vec.Coord) int { return costs[pos.ToIndex(area.W)] },
+	}
+
+	return
+}
+
+var testMaze = []string{
+	"..#...",
+	"#.#.#.",
+	"#...##",
+}
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        []string
+		movement    Movement
+		start, goal vec.Coord
+		path        []vec.Coord // nil if there should be no path
+	}{
+		{"4way maze", testMaze, MOVE_4WAY, vec.Coord{0, 0}, vec.Coord{5, 1}, []vec.Coord{
+			{1, 0}, {1, 1}, {1, 2}, {2, 2}, {3, 2}, {3, 1}, {3, 0}, {4, 0}, {5, 0}, {5, 1},
+		}},
+		{"8way maze", testMaze, MOVE_8WAY, vec.Coord{0, 0}, vec.Coord{5, 1}, []vec.Coord{
+			{1, 1}, {2, 2}, {3, 1}, {4, 0}, {5, 1},
+		}},
+		{"4way around expensive tiles", []string{
+			"...",
+			".9.",
+			"...",
+		}, MOVE_4WAY, vec.Coord{1, 0}, vec.Coord{1, 2}, nil},
+		{"blocked goal", []string{"..#"}, MOVE_4WAY, vec.Coord{0, 0}, vec.Coord{2, 0}, []vec.Coord{{1, 0}, {2, 0}}},
+		{"4way unreachable", []string{
+			"..#.",
+			"..#.",
+		}, MOVE_4WAY, vec.Coord{0, 0}, vec.Coord{3, 1}, nil},
+		{"8way unreachable", []string{
+			"..#.",
+			"..#.",
+		}, MOVE_8WAY, vec.Coord{0, 0}, vec.Coord{3, 1}, nil},
+		{"8way diagonal gap", []string{
+			".#",
+			"#.",
+		}, MOVE_8WAY, vec.Coord{0, 0}, vec.Coord{1, 1}, []vec.Coord{{1, 1}}},
+		{"start is goal", testMaze, MOVE_4WAY, vec.Coord{1, 1}, vec.Coord{1, 1}, []vec.Coord{}},
+		{"goal out of bounds", testMaze, MOVE_4WAY, vec.Coord{0, 0}, vec.Coord{10, 0}, nil},
+	}
+
+	for _, test := range tests {
+		graph, _ := gridGraph(test.rows, test.movement)
+		path, ok := graph.FindPath(test.start, test.goal)
+
+		// going around the 9 costs 4, going through it costs 10. either way round is fine.
+		if test.name == "4way around expensive tiles" {
+			if !ok || len(path) != 4 || slices.Contains(path, vec.Coord{1, 1}) {
+				t.Errorf("%s: got path %v, wanted a 4 step path around the centre", test.name, path)
+			}
+			continue
+		}
+
+		if ok != (test.path != nil) || !slices.Equal(path, test.path) {
+			t.Errorf("%s: got path %v (ok %v), wanted %v", test.name, path, ok, test.path)
+		}
+	}
+}
+
+// checks every value in the map against a freshly built one.
+func compareDijkstraMaps(t *testing.T, name string, dm *DijkstraMap, graph Graph, goals []vec.Coord) bool {
+	t.Helper()
+
+	fresh := NewDijkstraMap(graph, goals...)
+	for pos := range graph.Area.EachCoord() {
+		if got, want := dm.Value(pos), fresh.Value(pos); got != want {
+			t.Errorf("%s: value at %v is %d, rebuilt map has %d", name, pos, got, want)
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestDijkstraMap(t *testing.T) {
+	graph, _ := gridGraph(testMaze, MOVE_4WAY)
+	dm := NewDijkstraMap(graph, vec.Coord{5, 1})
+
+	if value := dm.Value(vec.Coord{0, 0}); value != 10 {
+		t.Errorf("Value at start of maze is %d, wanted 10", value)
+	}
+
+	if value := dm.Value(vec.Coord{0, 1}); value != 9 {
+		t.Errorf("Value of wall next to the start is %d, wanted 9 (walls get values, but aren't pathed through)", value)
+	}
+
+	if next, ok := dm.Next(vec.Coord{1, 2}); !ok || next != (vec.Coord{2, 2}) {
+		t.Errorf("Next step from %v is %v, wanted %v", vec.Coord{1, 2}, next, vec.Coord{2, 2})
+	}
+
+	if _, ok := dm.Next(vec.Coord{5, 1}); ok {
+		t.Error("Got a next step from the goal.")
+	}
+
+	unreachable, _ := gridGraph([]string{"..#."}, MOVE_4WAY)
+	if value := NewDijkstraMap(unreachable, vec.Coord{3, 0}).Value(vec.Coord{0, 0}); value != UNREACHABLE {
+		t.Errorf("Unreachable position has value %d", value)
+	}
+}
+
+// makes random changes to the costs of a graph, checking that updating the map incrementally always gives the same
+// result as rebuilding it.
+func TestDijkstraMapUpdates(t *testing.T) {
+	rows := []string{
+		"..........",
+		"..#####...",
+		"..#...#...",
+		"..#.3.#.2.",
+		"......#...",
+		"..#####...",
+		"..........",
+	}
+
+	for _, movement := range []Movement{MOVE_4WAY, MOVE_8WAY} {
+		name := map[Movement]string{MOVE_4WAY: "4way", MOVE_8WAY: "8way"}[movement]
+		graph, costs := gridGraph(rows, movement)
+		goals := []vec.Coord{{4, 3}, {9, 6}}
+		dm := NewDijkstraMap(graph, goals...)
+		dm.Value(vec.ZERO_COORD) // build it
+
+		rng := rand.New(rand.NewPCG(1, 2))
+		options := []int{IMPASSABLE, 1, 1, 2, 5}
+		for step := range 300 {
+			// change a few positions at once, sometimes including the goals
+			for range rng.IntN(3) + 1 {
+				pos := vec.Coord{rng.IntN(graph.Area.W), rng.IntN(graph.Area.H)}
+				if step%25 == 0 {
+					pos = goals[rng.IntN(len(goals))]
+				}
+
+				costs[pos.ToIndex(graph.Area.W)] = options[rng.IntN(len(options))]
+				dm.MarkChanged(pos)
+			}
+
+			if !compareDijkstraMaps(t, name, dm, graph, goals) {
+				t.Fatalf("%s: incremental update diverged from rebuild after %d changes", name, step+1)
+			}
+		}
+	}
+}
+
+func TestDijkstraMapCostChanges(t *testing.T) {
+	graph, costs := gridGraph(testMaze, MOVE_4WAY)
+	goals := []vec.Coord{{5, 1}}
+	dm := NewDijkstraMap(graph, goals...)
+
+	changes := []struct {
+		name string
+		pos  vec.Coord
+		cost int
+	}{
+		{"wall added across only route", vec.Coord{3, 1}, IMPASSABLE},
+		{"wall removed", vec.Coord{3, 1}, 1},
+		{"cost increased", vec.Coord{1, 1}, 5},
+		{"cost decreased", vec.Coord{1, 1}, 1},
+		{"shortcut opened", vec.Coord{2, 1}, 1},
+		{"goal made impassable", vec.Coord{5, 1}, IMPASSABLE},
+		{"shortcut closed", vec.Coord{2, 1}, IMPASSABLE},
+	}
+
+	for _, change := range changes {
+		costs[change.pos.ToIndex(graph.Area.W)] = change.cost
+		dm.MarkChanged(change.pos)
+		compareDijkstraMaps(t, change.name, dm, graph, goals)
+	}
+}
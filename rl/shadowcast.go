@@ -1,6 +1,8 @@
 package rl
 
 import (
+	"math"
+
 	"github.com/bennicholls/tyumi/util"
 	"github.com/bennicholls/tyumi/vec"
 )
@@ -21,6 +23,41 @@ func (m *TileMap) ShadowCast(pos vec.Coord, radius int, fn Cast) {
 	}
 }
 
+// ShadowCastCone is like ShadowCast, but only applies fn to spaces within a cone facing the provided direction. arc is
+// the width of the cone in degrees. If arc is 0 or at least 360, or facing is DIR_NONE, this is a regular ShadowCast.
+func (m *TileMap) ShadowCastCone(pos vec.Coord, radius int, facing vec.Direction, arc int, fn Cast) {
+	m.ShadowCast(pos, radius, ConeCast(pos, facing, arc, fn))
+}
+
+// ConeCast wraps fn so it is only applied to spaces within a cone originating at pos. See InCone(). If arc is 0 or at
+// least 360, or facing is DIR_NONE, fn is returned unchanged.
+func ConeCast(pos vec.Coord, facing vec.Direction, arc int, fn Cast) Cast {
+	if arc <= 0 || arc >= 360 || facing == vec.DIR_NONE {
+		return fn
+	}
+
+	return func(tm *TileMap, cast_pos vec.Coord, d2, r int) {
+		if InCone(pos, cast_pos, facing, arc) {
+			fn(tm, cast_pos, d2, r)
+		}
+	}
+}
+
+// InCone reports whether target lies within the cone originating at pos, facing the provided direction, with a width
+// of arc degrees. The origin itself is always in the cone.
+func InCone(pos, target vec.Coord, facing vec.Direction, arc int) bool {
+	if pos == target || arc >= 360 || facing == vec.DIR_NONE {
+		return true
+	}
+
+	delta, dir := target.Subtract(pos), facing.Coord()
+	angle := math.Atan2(float64(delta.Y), float64(delta.X)) - math.Atan2(float64(dir.Y), float64(dir.X))
+	angle = math.Abs(math.Remainder(angle, 2*math.Pi))
+
+	// small fudge so spaces lying exactly on the edge of the cone are included
+	return angle <= float64(arc)*math.Pi/360+1e-6
+}
+
 // NOTE: The 'cull' bool controls the logic for ensuring the 8 passes don't overlap at the edges. It is set to true for
 // the odd-numbered scans. The shadowcaster still visits these squares twice, but the function fn is not run twice.
 // Trust me Ben, this was the best way you could think of and your other solutions created crazy behaviour. Leave it alone!
@@ -73,6 +110,138 @@ func (tm *TileMap) scan(pos vec.Coord, row int, slope1, slope2 float32, radius i
 	}
 }
 
+// FOVAlgorithm selects which algorithm is used to compute a field of view. See TileMap.CastFOV().
+type FOVAlgorithm uint8
+
+const (
+	FOV_SHADOWCAST FOVAlgorithm = iota // the classic recursive shadowcaster. fast, but not symmetric.
+	FOV_SYMMETRIC                      // symmetric shadowcasting. if a can see b, b can see a. walls block light as diamonds.
+	FOV_DIAMOND                        // diamond walls. like FOV_SYMMETRIC but any tile partially in view is seen. more permissive, not symmetric.
+)
+
+// CastFOV runs the selected FOV algorithm from pos, applying fn to every space found within the radius.
+func (m *TileMap) CastFOV(algorithm FOVAlgorithm, pos vec.Coord, radius int, fn Cast) {
+	switch algorithm {
+	case FOV_SYMMETRIC:
+		m.SymmetricShadowCast(pos, radius, fn)
+	case FOV_DIAMOND:
+		m.DiamondShadowCast(pos, radius, fn)
+	default:
+		m.ShadowCast(pos, radius, fn)
+	}
+}
+
+// SymmetricShadowCast is a shadowcaster with the nice property that visibility is symmetric: if pos can see a space,
+// an observer in that space can see pos. Floors are only visible if their center can be seen, while walls are visible
+// if any part of them can be. Good for stealth games and such where fairness matters.
+// Based on Albert Ford's Symmetric Shadowcasting: https://www.albertford.com/shadowcasting/
+func (m *TileMap) SymmetricShadowCast(pos vec.Coord, radius int, fn Cast) {
+	m.quadrantCast(pos, radius, true, fn)
+}
+
+// DiamondShadowCast treats walls as diamonds and reveals any space whose diamond is partially in view. This gives
+// a more permissive view around pillars and corners than the symmetric shadowcaster, at the cost of symmetry.
+func (m *TileMap) DiamondShadowCast(pos vec.Coord, radius int, fn Cast) {
+	m.quadrantCast(pos, radius, false, fn)
+}
+
+// runs the symmetric-style shadowcaster over the 4 cardinal quadrants. spaces on the diagonals are shared by 2
+// quadrants so we keep track of what we've visited to make sure fn is only run once per space.
+func (m *TileMap) quadrantCast(pos vec.Coord, radius int, symmetric bool, fn Cast) {
+	if radius <= 0 {
+		return
+	}
+	fn(m, pos, 0, radius)
+
+	var visited util.Set[vec.Coord]
+	reveal := func(cast_pos vec.Coord) {
+		if d := pos.DistanceSqTo(cast_pos); d < radius*radius && !visited.Contains(cast_pos) {
+			visited.Add(cast_pos)
+			fn(m, cast_pos, d, radius)
+		}
+	}
+
+	for _, dir := range vec.CardinalDirections {
+		q := quadrant{origin: pos, forward: dir.Coord(), side: dir.RotateCW90().Coord()}
+		m.scanRow(q, 1, slope{-1, 1}, slope{1, 1}, radius, symmetric, reveal)
+	}
+}
+
+// a quadrant of the symmetric shadowcaster. rows are scanned moving forward, columns across them moving sideways.
+type quadrant struct {
+	origin, forward, side vec.Coord
+}
+
+func (q quadrant) transform(depth, col int) vec.Coord {
+	return q.origin.Add(q.forward.Scale(depth)).Add(q.side.Scale(col))
+}
+
+// slopes are kept as fractions so the symmetric shadowcaster doesn't suffer from floating point weirdness.
+type slope struct {
+	num, den int // den is always positive
+}
+
+// slope through the left edge of the space at (depth, col), measured at the space's center row.
+func edgeSlope(depth, col int) slope {
+	return slope{2*col - 1, 2 * depth}
+}
+
+// integer division rounding toward negative infinity. b must be positive.
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+
+	return a / b
+}
+
+func (tm *TileMap) scanRow(q quadrant, depth int, start, end slope, radius int, symmetric bool, reveal func(vec.Coord)) {
+	if depth > radius {
+		return
+	}
+
+	// columns whose center row overlaps the range of slopes. round half up for the min, half down for the max.
+	minCol := floorDiv(2*depth*start.num+start.den, 2*start.den)
+	maxCol := -floorDiv(-(2*depth*end.num - end.den), 2*end.den)
+
+	const (
+		NONE = iota
+		WALL
+		FLOOR
+	)
+	prev := NONE
+
+	for col := minCol; col <= maxCol; col++ {
+		mapPos := q.transform(depth, col)
+		inBounds := tm.Bounds().Contains(mapPos)
+		wall := !inBounds || tm.IsTileOpaque(mapPos)
+
+		if inBounds {
+			// center of the space lies within the slopes
+			centered := col*start.den >= depth*start.num && col*end.den <= depth*end.num
+			if wall || !symmetric || centered {
+				reveal(mapPos)
+			}
+		}
+
+		if prev == WALL && !wall {
+			start = edgeSlope(depth, col)
+		} else if prev == FLOOR && wall {
+			tm.scanRow(q, depth+1, start, edgeSlope(depth, col), radius, symmetric, reveal)
+		}
+
+		if wall {
+			prev = WALL
+		} else {
+			prev = FLOOR
+		}
+	}
+
+	if prev == FLOOR {
+		tm.scanRow(q, depth+1, start, end, radius, symmetric, reveal)
+	}
+}
+
 // type specifying precisely what you can pass to the shadowcaster. parameters here are the info that the shadowcaster
 // will deliver. d2 is the distance squared from the center of the cast.
 type Cast func(tm *TileMap, pos vec.Coord, d2, r int)
@@ -1,15 +1,26 @@
 package rl
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 
 	"github.com/bennicholls/tyumi/util"
 	"golang.org/x/exp/constraints"
 )
 
+// Stat is a numeric value bounded by a min and max. Modifiers can be layered on top of the stat's base value (see
+// SetModifier()), so temporary changes like "+2 strength for 10 turns" can be reverted cleanly.
 type Stat[T constraints.Float | constraints.Integer] struct {
-	value    T
-	min, max T
+	value     T
+	min, max  T
+	modifiers []StatModifier[T]
+}
+
+// StatModifier is a change to a stat's value from some source (a status effect, a piece of equipment, etc.).
+type StatModifier[T constraints.Float | constraints.Integer] struct {
+	Source string
+	Amount T
 }
 
 func NewStat[T constraints.Float | constraints.Integer](value, min, max T) Stat[T] {
@@ -28,16 +39,86 @@ func NewBasicStat[T constraints.Float | constraints.Integer](value T) Stat[T] {
 	}
 }
 
+// Get returns the value of the stat, including any modifiers. The result is always kept within the min and max.
 func (s Stat[T]) Get() T {
+	return util.Clamp(s.value+s.modifierTotal(), s.min, s.max)
+}
+
+// Base returns the value of the stat without any modifiers. While modifiers are applied this can be outside of the
+// min and max.
+func (s Stat[T]) Base() T {
 	return s.value
 }
 
+func (s Stat[T]) modifierTotal() (total T) {
+	for _, modifier := range s.modifiers {
+		total += modifier.Amount
+	}
+
+	return
+}
+
+// SetModifier adds a modifier to the stat, identified by its source. If the source already has a modifier on the
+// stat, it is replaced. Changes made with Mod() while a modifier is applied go to the base value, so they stick around
+// after the modifier is removed. For example, 3 damage taken while a +5 HP modifier is applied is still missing once
+// the modifier is gone. If modifiers push the stat past its max, the excess is hidden by Get() but still soaks up
+// reductions: at 10/10 HP with a +5 modifier, 3 damage leaves the stat at 10/10 and removing the modifier leaves 7/10.
+// Note that this means removing a modifier can bring the stat down to its minimum.
+func (s *Stat[T]) SetModifier(source string, amount T) {
+	// modifier lists are never changed in place, so copies of the stat don't share changes
+	modifiers := slices.DeleteFunc(slices.Clone(s.modifiers), func(m StatModifier[T]) bool { return m.Source == source })
+	s.modifiers = append(modifiers, StatModifier[T]{Source: source, Amount: amount})
+}
+
+// RemoveModifier removes the modifier from the provided source, if there is one.
+func (s *Stat[T]) RemoveModifier(source string) {
+	if !slices.ContainsFunc(s.modifiers, func(m StatModifier[T]) bool { return m.Source == source }) {
+		return
+	}
+
+	s.modifiers = slices.DeleteFunc(slices.Clone(s.modifiers), func(m StatModifier[T]) bool { return m.Source == source })
+	if len(s.modifiers) == 0 {
+		s.modifiers = nil
+	}
+
+	s.Mod(0) // bring the base back in bounds, it can only be outside them while modified
+}
+
+// Modifiers returns the modifiers currently applied to the stat.
+func (s Stat[T]) Modifiers() []StatModifier[T] {
+	return slices.Clone(s.modifiers)
+}
+
+// Set sets the value of the stat, including modifiers, so afterwards Get() returns v (kept within the min and max). The
+// base value is set to whatever gives that result with the current modifiers.
 func (s *Stat[T]) Set(v T) {
-	s.value = util.Clamp(v, s.min, s.max)
+	s.value = util.Clamp(v, s.min, s.max) - s.modifierTotal()
 }
 
+// Mod changes the base value of the stat by dv. The result is kept within bounds: the modified value can't go below
+// the min, and the base can't go above the max (nor can the modified value, if the modifiers are negative). See
+// SetModifier() for how this works while modifiers are applied.
 func (s *Stat[T]) Mod(dv T) {
-	s.Set(s.value + dv)
+	total := s.modifierTotal()
+	s.value = util.Clamp(s.value+dv, s.min-total, max(s.max, s.max-total))
+}
+
+// Absorb takes as much of the amount as it can out of the stat, down to the stat's minimum, and returns whatever is left
+// over. Useful for things like shields that soak up damage.
+func (s *Stat[T]) Absorb(amount T) (remaining T) {
+	absorbed := min(amount, s.toMin())
+	if absorbed <= 0 {
+		return amount
+	}
+
+	s.Mod(-absorbed)
+	return amount - absorbed
+}
+
+// amount the stat can be reduced by before it hits its minimum. if modifiers push the stat past its max, this is more
+// than Get() - Min().
+func (s Stat[T]) toMin() T {
+	return max(s.value+s.modifierTotal()-s.min, 0)
 }
 
 func (s Stat[T]) Max() T {
@@ -55,17 +136,18 @@ func (s *Stat[T]) SetMin(m T) {
 	}
 
 	s.min = m
-	s.value = util.Clamp(s.value, s.min, s.max)
+	s.Mod(0)
 }
 
-// Sets a new maximum. If this would make max < min, does nothing.
+// Sets a new maximum. If this would make max < min, does nothing. Raising the max can reveal modifiers that were
+// pushing the stat past the old one.
 func (s *Stat[T]) SetMax(m T) {
 	if m < s.min {
 		return
 	}
 
 	s.max = m
-	s.value = util.Clamp(s.value, s.min, s.max)
+	s.Mod(0)
 }
 
 // Modifies the minimum. Takes a delta. Follows same rules as SetMin().
@@ -79,11 +161,11 @@ func (s *Stat[T]) ModMax(d T) {
 }
 
 func (s Stat[T]) IsMax() bool {
-	return s.value == s.max
+	return s.Get() == s.max
 }
 
 func (s Stat[T]) IsMin() bool {
-	return s.value == s.min
+	return s.Get() == s.min
 }
 
 // returns a % (0-100) for the stat[T]. If min == val == max, returns 0.
@@ -92,9 +174,34 @@ func (s Stat[T]) GetPct() int {
 		return 0
 	}
 
-	return int(100 * (float32(s.value-s.min) / float32(s.max-s.min)))
+	return int(100 * (float32(s.Get()-s.min) / float32(s.max-s.min)))
+}
+
+// stat fields are unexported, so we marshal them through this when saving.
+type statJSON[T constraints.Float | constraints.Integer] struct {
+	Value, Min, Max T
+	Modifiers       []StatModifier[T] `json:",omitempty"`
+}
+
+func (s Stat[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(statJSON[T]{s.value, s.min, s.max, s.modifiers})
+}
+
+func (s *Stat[T]) UnmarshalJSON(data []byte) error {
+	var sj statJSON[T]
+	if err := json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+
+	// the base value is saved as-is, since it can be outside the min and max while modifiers are applied
+	*s = Stat[T]{value: sj.Value, min: sj.Min, max: sj.Max, modifiers: sj.Modifiers}
+	if len(s.modifiers) == 0 {
+		s.value = util.Clamp(s.value, s.min, s.max)
+	}
+
+	return nil
 }
 
 func (s Stat[T]) String() string {
-	return fmt.Sprintf("%v/%v", s.value, s.max)
+	return fmt.Sprintf("%v/%v", s.Get(), s.max)
 }
@@ -0,0 +1,148 @@
+package rl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bennicholls/tyumi/rl/ecs"
+)
+
+func TestStatModifiers(t *testing.T) {
+	s := NewStat(5, 0, 10)
+
+	s.SetModifier("ring", 2)
+	if s.Get() != 7 || s.Base() != 5 {
+		t.Errorf("Applying modifier: got %d (base %d), wanted 7 (base 5)", s.Get(), s.Base())
+	}
+
+	s.SetModifier("potion", 1)
+	if s.Get() != 8 {
+		t.Errorf("Stacking modifiers: got %d, wanted 8", s.Get())
+	}
+
+	s.SetModifier("ring", 3)
+	if s.Get() != 9 || len(s.Modifiers()) != 2 {
+		t.Errorf("Replacing modifier: got %d with %d modifiers, wanted 9 with 2", s.Get(), len(s.Modifiers()))
+	}
+
+	s.SetModifier("giant", 20)
+	if s.Get() != 10 {
+		t.Errorf("Modified stat went over max: got %d, wanted 10", s.Get())
+	}
+
+	s.RemoveModifier("giant")
+	s.RemoveModifier("ring")
+	if s.Get() != 6 {
+		t.Errorf("Removing modifier: got %d, wanted 6", s.Get())
+	}
+
+	s.RemoveModifier("potion")
+	if s.Get() != 5 || s.Modifiers() != nil {
+		t.Errorf("Removing all modifiers: got %d with modifiers %v, wanted 5 with none", s.Get(), s.Modifiers())
+	}
+}
+
+func TestStatModifiedChanges(t *testing.T) {
+	s := NewBasicStat(10)
+	s.SetModifier("buff", 5)
+
+	// at max, so the buff doesn't show, but it soaks up damage until the base drops low enough for it to show
+	s.Mod(-3)
+	if s.Get() != 10 || s.Base() != 7 {
+		t.Errorf("Mod while modified: got %d (base %d), wanted 10 (base 7)", s.Get(), s.Base())
+	}
+
+	s.Mod(-4)
+	if s.Get() != 8 || s.Base() != 3 {
+		t.Errorf("Mod while modified: got %d (base %d), wanted 8 (base 3)", s.Get(), s.Base())
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal("Could not marshal stat: ", err)
+	}
+
+	var loaded Stat[int]
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal("Could not unmarshal stat: ", err)
+	}
+
+	if loaded.Get() != 8 || loaded.Base() != 3 || len(loaded.Modifiers()) != 1 {
+		t.Errorf("Modified stat loaded incorrectly: %d (base %d)", loaded.Get(), loaded.Base())
+	}
+
+	// 7 damage was taken while buffed
+	s.RemoveModifier("buff")
+	if s.Get() != 3 {
+		t.Errorf("Removing modifier: got %d, wanted 3", s.Get())
+	}
+
+	tests := []struct {
+		name     string
+		change   func(s *Stat[int])
+		modified int // value while the modifier is still applied
+		removed  int // value after removing the modifier
+	}{
+		{"damage at max", func(s *Stat[int]) { s.Mod(-1) }, 10, 9},
+		{"damage then heal", func(s *Stat[int]) { s.Mod(-5); s.Mod(3) }, 10, 8},
+		{"overheal", func(s *Stat[int]) { s.Mod(-1); s.Mod(5) }, 10, 10},
+		{"damage past the buff", func(s *Stat[int]) { s.Mod(-4) }, 8, 6},
+		{"killed", func(s *Stat[int]) { s.Mod(-20) }, 0, 0},
+		{"absorb", func(s *Stat[int]) { s.Absorb(5) }, 7, 5},
+		{"set", func(s *Stat[int]) { s.Set(4) }, 4, 2},
+		{"raise max", func(s *Stat[int]) { s.Mod(-1); s.SetMax(20) }, 11, 9},
+		{"lower max", func(s *Stat[int]) { s.SetMax(5) }, 5, 5},
+	}
+
+	for _, test := range tests {
+		s := NewStat(10, 0, 10)
+		s.SetModifier("buff", 2)
+		test.change(&s)
+		if s.Get() != test.modified {
+			t.Errorf("%s: got %d while modified, wanted %d", test.name, s.Get(), test.modified)
+		}
+
+		s.RemoveModifier("buff")
+		if s.Get() != test.removed {
+			t.Errorf("%s: got %d after removing modifier, wanted %d", test.name, s.Get(), test.removed)
+		}
+	}
+
+	// negative modifiers can't be healed through
+	s = NewStat(10, 0, 10)
+	s.SetModifier("curse", -2)
+	s.Mod(5)
+	if s.Get() != 10 || s.Base() != 12 {
+		t.Errorf("Healing while cursed: got %d (base %d), wanted 10 (base 12)", s.Get(), s.Base())
+	}
+
+	s.RemoveModifier("curse")
+	if s.Get() != 10 || s.Base() != 10 {
+		t.Errorf("Removing curse: got %d (base %d), wanted 10 (base 10)", s.Get(), s.Base())
+	}
+
+	if s.Absorb(15) != 5 || s.Get() != 0 {
+		t.Errorf("Absorb past minimum: left %d", s.Get())
+	}
+}
+
+func TestDamageWhileModified(t *testing.T) {
+	rat := CreateEntity(saveTestRat)
+	defer ecs.DestroyEntity(rat)
+
+	health := ecs.Get[HealthComponent](rat)
+	health.HP.SetModifier("blessed", 3)
+
+	// the blessing can't push HP past max, so it soaks up the first 3 damage
+	if dealt := health.Damage(5, DAMAGE_TRUE); dealt != 5 || health.HP.Get() != 5 {
+		t.Errorf("Damage while modified: dealt %d leaving %d HP, wanted 5 leaving 5", dealt, health.HP.Get())
+	}
+
+	if dealt := health.Damage(100, DAMAGE_TRUE); dealt != 5 || !health.IsDead() {
+		t.Errorf("Modified entity survived: dealt %d leaving %d HP", dealt, health.HP.Get())
+	}
+
+	if health.Heal(5) != 0 {
+		t.Error("Healed a dead entity.")
+	}
+}
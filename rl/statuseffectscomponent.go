@@ -0,0 +1,430 @@
+package rl
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/log"
+	"github.com/bennicholls/tyumi/rl/ecs"
+	"github.com/bennicholls/tyumi/util"
+	"golang.org/x/exp/constraints"
+)
+
+func init() {
+	ecs.Register[StatusEffectsComponent]("rl.StatusEffects")
+}
+
+var (
+	EV_STATUSEFFECTAPPLIED = event.Register("A status effect was applied to an entity.")
+	EV_STATUSEFFECTEXPIRED = event.Register("A status effect on an entity expired or was removed.")
+)
+
+type StatusEffectEvent struct {
+	event.EventPrototype
+
+	Entity Entity
+	Effect StatusEffectType
+	Stacks int
+}
+
+// StackingRule determines what happens when a status effect is applied to an entity that already has it.
+type StackingRule uint8
+
+const (
+	STACK_REFRESH  StackingRule = iota // the duration is reset, unless more than that remains. this is the default.
+	STACK_DURATION                     // the new duration is added to the remaining duration
+	// another stack is added (up to MaxStacks) and the duration is reset like STACK_REFRESH. modifiers are scaled by
+	// the number of stacks.
+	STACK_INTENSITY
+	STACK_IGNORE // nothing happens, the existing effect runs its course
+)
+
+type StatusEffectType uint32
+
+func (st StatusEffectType) GetData() StatusEffectData {
+	return statusEffectDataCache.GetData(st)
+}
+
+// status effect types are saved by name, so saves don't break if effects are registered in a different order.
+func (st StatusEffectType) MarshalText() ([]byte, error) {
+	return []byte(st.GetData().Name), nil
+}
+
+func (st *StatusEffectType) UnmarshalText(text []byte) error {
+	effectType, ok := GetStatusEffectTypeByName(string(text))
+	if !ok {
+		return fmt.Errorf("no status effect type registered with name %q", text)
+	}
+
+	*st = effectType
+	return nil
+}
+
+// StatusEffectData describes a type of status effect. While the effect is active its Modifiers are applied to the
+// entity; for anything the modifiers can't express, use the callbacks. OnApply and OnRemove are only called when the
+// effect is first applied and when it is finally removed, not each time it is reapplied, so they are a good place
+// for changes that have to be reverted. Callbacks are given a copy of the effect; to change the effect itself, get it
+// from the entity's StatusEffectsComponent.
+type StatusEffectData struct {
+	Name      string
+	Desc      string
+	Duration  int // default duration in turns. if 0, the effect is permanent until it is removed.
+	Stacking  StackingRule
+	MaxStacks int              // maximum number of stacks for STACK_INTENSITY effects. if 0, there is no limit.
+	Modifiers []StatusModifier // applied to the entity while the effect is active
+
+	OnApply  func(entity Entity, effect StatusEffect)
+	OnRemove func(entity Entity, effect StatusEffect)
+	OnTick   func(entity Entity, effect StatusEffect) // called at the end of each of the entity's turns
+}
+
+var statusEffectDataCache util.DataCache[StatusEffectData, StatusEffectType]
+var statusEffectTypeNames map[string]StatusEffectType = make(map[string]StatusEffectType)
+
+// RegisterStatusEffectType registers a new type of status effect. Like entity types, status effects are saved by name
+// so names should be unique and shouldn't change once you have saves you care about.
+func RegisterStatusEffectType(effect_data StatusEffectData) StatusEffectType {
+	effectType := statusEffectDataCache.RegisterDataType(effect_data)
+	if _, ok := statusEffectTypeNames[effect_data.Name]; ok {
+		log.Warning("Status effect name ", effect_data.Name, " is already in use! Effects of this type will not load correctly.")
+	} else {
+		statusEffectTypeNames[effect_data.Name] = effectType
+	}
+
+	return effectType
+}
+
+// GetStatusEffectTypeByName returns the registered status effect type with the provided name.
+func GetStatusEffectTypeByName(name string) (effect_type StatusEffectType, ok bool) {
+	effect_type, ok = statusEffectTypeNames[name]
+	return
+}
+
+// Some common status effects.
+var (
+	// STATUS_POISONED deals 1 poison damage per stack at the end of each turn.
+	STATUS_POISONED = RegisterStatusEffectType(StatusEffectData{
+		Name:     "Poisoned",
+		Desc:     "Taking poison damage every turn.",
+		Duration: 10,
+		Stacking: STACK_INTENSITY,
+		OnTick: func(entity Entity, effect StatusEffect) {
+			if health := ecs.Get[HealthComponent](entity); health != nil {
+				health.Damage(effect.Stacks, DAMAGE_POISON)
+			}
+		},
+	})
+
+	// STATUS_HASTED gives the entity an extra SPEED_NORMAL worth of speed, so a normal-speed actor acts twice as often.
+	STATUS_HASTED = RegisterStatusEffectType(StatusEffectData{
+		Name:     "Hasted",
+		Desc:     "Moving at twice the normal speed.",
+		Duration: 10,
+		OnApply: func(entity Entity, effect StatusEffect) {
+			if actor := ecs.Get[ActorComponent](entity); actor != nil {
+				actor.Speed += SPEED_NORMAL
+			}
+		},
+		OnRemove: func(entity Entity, effect StatusEffect) {
+			if actor := ecs.Get[ActorComponent](entity); actor != nil {
+				actor.Speed -= SPEED_NORMAL
+			}
+		},
+	})
+
+	// STATUS_BLINDED blinds the entity, see FOVComponent.Blind.
+	STATUS_BLINDED = RegisterStatusEffectType(StatusEffectData{
+		Name:      "Blinded",
+		Desc:      "Unable to see.",
+		Duration:  5,
+		Modifiers: []StatusModifier{BlindModifier},
+	})
+)
+
+// StatusModifier is something a status effect changes about an entity while it is active. Use ModifyStat() to make
+// modifiers for stats.
+type StatusModifier interface {
+	apply(entity Entity, source string, stacks int)
+	remove(entity Entity, source string)
+}
+
+type statModifier[T constraints.Float | constraints.Integer] struct {
+	stat   func(entity Entity) *Stat[T]
+	amount T
+}
+
+// ModifyStat creates a modifier that changes a stat by the provided amount (multiplied by the number of stacks). The
+// stat function should return the stat to be modified, or nil if the entity doesn't have it. For example:
+//
+//	ModifyStat(func(e Entity) *Stat[int] { return &ecs.Get[AttributesComponent](e).Strength }, 2)
+func ModifyStat[T constraints.Float | constraints.Integer](stat func(entity Entity) *Stat[T], amount T) StatusModifier {
+	return statModifier[T]{stat: stat, amount: amount}
+}
+
+func (sm statModifier[T]) apply(entity Entity, source string, stacks int) {
+	if stat := sm.stat(entity); stat != nil {
+		stat.SetModifier(source, sm.amount*T(stacks))
+	}
+}
+
+func (sm statModifier[T]) remove(entity Entity, source string) {
+	if stat := sm.stat(entity); stat != nil {
+		stat.RemoveModifier(source)
+	}
+}
+
+type blindModifier struct{}
+
+// BlindModifier blinds the entity while the effect is active. The entity stays blind until every effect blinding it
+// has been removed.
+var BlindModifier StatusModifier = blindModifier{}
+
+func (blindModifier) apply(entity Entity, source string, stacks int) {
+	if fov := ecs.Get[FOVComponent](entity); fov != nil {
+		fov.SetBlind(true)
+	}
+}
+
+func (blindModifier) remove(entity Entity, source string) {
+	if effects := ecs.Get[StatusEffectsComponent](entity); effects != nil && effects.isBlinded() {
+		return
+	}
+
+	if fov := ecs.Get[FOVComponent](entity); fov != nil {
+		fov.SetBlind(false)
+	}
+}
+
+// StatusEffect is a status effect currently active on an entity.
+type StatusEffect struct {
+	Type   StatusEffectType
+	Turns  int // turns remaining. if 0, the effect is permanent until removed.
+	Stacks int
+}
+
+// StatusEffectsComponent holds the status effects active on an entity. Use ApplyStatusEffect() to add effects; it adds
+// this component if necessary. Effects tick down at the end of each of the entity's turns (see StatusEffectSystem).
+type StatusEffectsComponent struct {
+	ecs.Component
+
+	Effects []StatusEffect
+}
+
+// ApplyStatusEffect applies a status effect to the entity. Optionally takes a duration in turns, otherwise the
+// effect's default duration is used. A duration of 0 makes the effect permanent. If the entity already has the effect,
+// what happens depends on the effect's StackingRule.
+func ApplyStatusEffect(entity Entity, effect_type StatusEffectType, duration ...int) {
+	if !ecs.Alive(entity) {
+		log.Error("Cannot apply status effect to dead/invalid entity.")
+		return
+	}
+
+	if !ecs.Has[StatusEffectsComponent](entity) {
+		ecs.Add[StatusEffectsComponent](entity)
+	}
+
+	data := effect_type.GetData()
+	turns := data.Duration
+	if len(duration) > 0 {
+		turns = max(duration[0], 0)
+	}
+
+	ecs.Get[StatusEffectsComponent](entity).apply(effect_type, turns)
+}
+
+func (sec *StatusEffectsComponent) apply(effect_type StatusEffectType, turns int) {
+	entity := Entity(sec.GetEntity())
+	data := effect_type.GetData()
+
+	if effect := sec.Get(effect_type); effect == nil {
+		sec.Effects = append(sec.Effects, StatusEffect{Type: effect_type, Turns: turns, Stacks: 1})
+		if data.OnApply != nil {
+			data.OnApply(entity, sec.Effects[len(sec.Effects)-1])
+			if sec = getStatusEffects(entity); sec == nil || !sec.Has(effect_type) {
+				return
+			}
+		}
+	} else {
+		switch data.Stacking {
+		case STACK_REFRESH:
+			effect.Turns = stackedDuration(effect.Turns, max(effect.Turns, turns), turns)
+		case STACK_DURATION:
+			effect.Turns = stackedDuration(effect.Turns, effect.Turns+turns, turns)
+		case STACK_INTENSITY:
+			if data.MaxStacks == 0 || effect.Stacks < data.MaxStacks {
+				effect.Stacks += 1
+			}
+			effect.Turns = stackedDuration(effect.Turns, max(effect.Turns, turns), turns)
+		case STACK_IGNORE:
+			return
+		}
+	}
+
+	stacks := sec.Get(effect_type).Stacks
+	for _, modifier := range data.Modifiers {
+		modifier.apply(entity, data.Name, stacks)
+	}
+
+	event.Fire(EV_STATUSEFFECTAPPLIED, &StatusEffectEvent{Entity: entity, Effect: effect_type, Stacks: stacks})
+}
+
+// permanent effects stay permanent, and reapplying an effect permanently makes it permanent. otherwise the effect
+// gets the stacked duration.
+func stackedDuration(current, stacked, applied int) int {
+	if current == 0 || applied == 0 {
+		return 0
+	}
+
+	return stacked
+}
+
+// Has reports whether the effect is active.
+func (sec *StatusEffectsComponent) Has(effect_type StatusEffectType) bool {
+	return sec.Get(effect_type) != nil
+}
+
+// Get returns the active effect of the provided type, or nil if the entity doesn't have it.
+func (sec *StatusEffectsComponent) Get(effect_type StatusEffectType) *StatusEffect {
+	if i := slices.IndexFunc(sec.Effects, func(e StatusEffect) bool { return e.Type == effect_type }); i != -1 {
+		return &sec.Effects[i]
+	}
+
+	return nil
+}
+
+// Remove removes the effect, reverting its modifiers. Fires EV_STATUSEFFECTEXPIRED.
+func (sec *StatusEffectsComponent) Remove(effect_type StatusEffectType) {
+	i := slices.IndexFunc(sec.Effects, func(e StatusEffect) bool { return e.Type == effect_type })
+	if i == -1 {
+		return
+	}
+
+	effect := sec.Effects[i]
+	sec.Effects = slices.Delete(sec.Effects, i, i+1)
+
+	entity := Entity(sec.GetEntity())
+	data := effect_type.GetData()
+	for _, modifier := range data.Modifiers {
+		modifier.remove(entity, data.Name)
+	}
+
+	if data.OnRemove != nil {
+		data.OnRemove(entity, effect)
+	}
+
+	event.Fire(EV_STATUSEFFECTEXPIRED, &StatusEffectEvent{Entity: entity, Effect: effect_type, Stacks: effect.Stacks})
+}
+
+// RemoveAll removes all active effects.
+func (sec *StatusEffectsComponent) RemoveAll() {
+	entity := Entity(sec.GetEntity())
+	for sec != nil && len(sec.Effects) > 0 {
+		sec.Remove(sec.Effects[0].Type)
+		sec = getStatusEffects(entity)
+	}
+}
+
+// Tick runs one turn's worth of time for the effects: OnTick callbacks are called, durations count down, and effects
+// that run out are removed. The StatusEffectSystem does this at the end of each of the entity's turns, but you can
+// call it yourself for entities that don't take turns.
+func (sec *StatusEffectsComponent) Tick() {
+	entity := Entity(sec.GetEntity())
+	expired := make([]StatusEffectType, 0)
+	for _, effect_type := range sec.types() {
+		// earlier callbacks might have removed the effect
+		effect := sec.Get(effect_type)
+		if effect == nil {
+			continue
+		}
+
+		if data := effect_type.GetData(); data.OnTick != nil {
+			data.OnTick(entity, *effect)
+			if sec = getStatusEffects(entity); sec == nil {
+				return
+			}
+
+			if effect = sec.Get(effect_type); effect == nil {
+				continue
+			}
+		}
+
+		if effect.Turns > 0 {
+			effect.Turns -= 1
+			if effect.Turns == 0 {
+				expired = append(expired, effect_type)
+			}
+		}
+	}
+
+	for _, effect_type := range expired {
+		sec.Remove(effect_type)
+		if sec = getStatusEffects(entity); sec == nil {
+			return
+		}
+	}
+}
+
+// callbacks can add and remove components, which moves components around in memory. so after calling them, the
+// StatusEffectsComponent has to be fetched again. returns nil if the entity or its effects are gone.
+func getStatusEffects(entity Entity) *StatusEffectsComponent {
+	if !ecs.Alive(entity) {
+		return nil
+	}
+
+	return ecs.Get[StatusEffectsComponent](entity)
+}
+
+func (sec *StatusEffectsComponent) types() (types []StatusEffectType) {
+	types = make([]StatusEffectType, len(sec.Effects))
+	for i, effect := range sec.Effects {
+		types[i] = effect.Type
+	}
+
+	return
+}
+
+// reports whether any active effect has a BlindModifier.
+func (sec *StatusEffectsComponent) isBlinded() bool {
+	for _, effect := range sec.Effects {
+		if slices.ContainsFunc(effect.Type.GetData().Modifiers, func(m StatusModifier) bool {
+			_, ok := m.(blindModifier)
+			return ok
+		}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StatusEffectSystem ticks the status effects of actors in the tilemap at the end of each of their turns.
+type StatusEffectSystem struct {
+	System
+
+	tileMap *TileMap
+}
+
+func (ses *StatusEffectSystem) Init(tm *TileMap) {
+	ses.tileMap = tm
+	ses.Listen(EV_TURNENDED)
+	ses.SetImmediateEventHandler(ses.immediateHandleEvent)
+}
+
+func (ses *StatusEffectSystem) immediateHandleEvent(e event.Event) (event_handled bool) {
+	switch e.ID() {
+	case EV_TURNENDED:
+		entity := e.(*EntityEvent).Entity
+		if !ses.tileMap.containsEntity(entity) {
+			break
+		}
+
+		if effects := getStatusEffects(entity); effects != nil {
+			effects.Tick()
+		}
+	default:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,95 @@
+package rl
+
+import (
+	"testing"
+
+	"github.com/bennicholls/tyumi/rl/ecs"
+)
+
+var (
+	testEffectBlessed = RegisterStatusEffectType(StatusEffectData{
+		Name:      "test_blessed",
+		Duration:  3,
+		Stacking:  STACK_INTENSITY,
+		MaxStacks: 2,
+		Modifiers: []StatusModifier{ModifyStat(func(e Entity) *Stat[int] { return &ecs.Get[HealthComponent](e).HP }, 2)},
+	})
+
+	testEffectMark     = RegisterStatusEffectType(StatusEffectData{Name: "test_mark"})
+	testEffectSpreadTo = RegisterStatusEffectType(StatusEffectData{Name: "test_spread_to"})
+
+	// spreads to itself and a bunch of new entities every tick, which moves status effects around in memory
+	testEffectSpreading = RegisterStatusEffectType(StatusEffectData{
+		Name:     "test_spreading",
+		Duration: 2,
+		OnTick: func(entity Entity, effect StatusEffect) {
+			ApplyStatusEffect(entity, testEffectSpreadTo)
+			for range 64 {
+				spreadTestEntities = append(spreadTestEntities, Entity(ecs.CreateEntity()))
+				ApplyStatusEffect(spreadTestEntities[len(spreadTestEntities)-1], testEffectMark)
+			}
+		},
+		OnRemove: func(entity Entity, effect StatusEffect) {
+			spreadTestRemoved = effect
+		},
+	})
+
+	spreadTestEntities []Entity
+	spreadTestRemoved  StatusEffect
+)
+
+func TestStatusEffectModifiers(t *testing.T) {
+	rat := CreateEntity(saveTestRat)
+	defer ecs.DestroyEntity(rat)
+
+	hp := func() int { return ecs.Get[HealthComponent](rat).HP.Get() }
+	ecs.Get[HealthComponent](rat).HP.Set(3)
+
+	ApplyStatusEffect(rat, testEffectBlessed)
+	if hp() != 5 {
+		t.Errorf("Applying effect: HP %d, wanted 5", hp())
+	}
+
+	ApplyStatusEffect(rat, testEffectBlessed)
+	ApplyStatusEffect(rat, testEffectBlessed) // past MaxStacks
+	if effect := ecs.Get[StatusEffectsComponent](rat).Get(testEffectBlessed); effect.Stacks != 2 || hp() != 7 {
+		t.Errorf("Stacking effect: %d stacks and %d HP, wanted 2 stacks and 7 HP", effect.Stacks, hp())
+	}
+
+	ecs.Get[HealthComponent](rat).Damage(4, DAMAGE_TRUE)
+	ecs.Get[StatusEffectsComponent](rat).Remove(testEffectBlessed)
+	if hp() != 0 || !ecs.Get[HealthComponent](rat).IsDead() {
+		t.Errorf("Removing effect after damage: HP %d, wanted 0", hp())
+	}
+}
+
+func TestStatusEffectTickMovesComponents(t *testing.T) {
+	spreadTestEntities = nil
+	entity := Entity(ecs.CreateEntity())
+	ApplyStatusEffect(entity, testEffectSpreading)
+	ApplyStatusEffect(entity, testEffectMark)
+
+	ecs.Get[StatusEffectsComponent](entity).Tick()
+	effects := ecs.Get[StatusEffectsComponent](entity)
+	if effect := effects.Get(testEffectSpreading); effect == nil || effect.Turns != 1 {
+		t.Fatalf("Effect did not tick down properly: %v", effect)
+	}
+
+	if !effects.Has(testEffectMark) || !effects.Has(testEffectSpreadTo) {
+		t.Error("Permanent effects lost while ticking.")
+	}
+
+	ecs.Get[StatusEffectsComponent](entity).Tick()
+	if ecs.Get[StatusEffectsComponent](entity).Has(testEffectSpreading) {
+		t.Error("Effect did not expire.")
+	}
+
+	if spreadTestRemoved.Type != testEffectSpreading || spreadTestRemoved.Stacks != 1 {
+		t.Errorf("OnRemove got the wrong effect: %v", spreadTestRemoved)
+	}
+
+	for _, spread := range spreadTestEntities {
+		ecs.DestroyEntity(spread)
+	}
+	ecs.DestroyEntity(entity)
+}
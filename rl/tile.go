@@ -1,7 +1,10 @@
 package rl
 
 import (
+	"slices"
+
 	"github.com/bennicholls/tyumi/gfx"
+	"github.com/bennicholls/tyumi/log"
 	"github.com/bennicholls/tyumi/rl/ecs"
 	"github.com/bennicholls/tyumi/util"
 	"github.com/bennicholls/tyumi/vec"
@@ -19,17 +22,34 @@ type TileData struct {
 	Visuals  gfx.Visuals
 	Passable bool
 	Opaque   bool
+	MoveCost int // cost of moving onto this tile, used for pathfinding. If 0, defaults to 1.
 }
 
 func (td TileData) GetVisuals() gfx.Visuals {
 	return td.Visuals
 }
 
+// RegisterTileType registers a new type of tile. Tile types are saved by name (see TileMap.Save()), so names should
+// be unique and shouldn't change once you have saves you care about.
 func RegisterTileType(tileData TileData) TileType {
-	return tileDataCache.RegisterDataType(tileData)
+	tileType := tileDataCache.RegisterDataType(tileData)
+	if _, ok := tileTypeNames[tileData.Name]; ok {
+		log.Warning("Tile type name ", tileData.Name, " is already in use! Tiles of this type will not load correctly.")
+	} else {
+		tileTypeNames[tileData.Name] = tileType
+	}
+
+	return tileType
+}
+
+// GetTileTypeByName returns the registered tile type with the provided name.
+func GetTileTypeByName(name string) (tile_type TileType, ok bool) {
+	tile_type, ok = tileTypeNames[name]
+	return
 }
 
 var tileDataCache util.DataCache[TileData, TileType]
+var tileTypeNames map[string]TileType = make(map[string]TileType)
 var TILE_NONE TileType
 
 // The function Tyumi will use to draw tiles. This is by default set to the provided DrawTile function. The visuals
@@ -75,8 +95,18 @@ func DrawTileEntity(tile Tile, viewer Entity) (vis gfx.Visuals, e Entity) {
 		return
 	}
 
+	// the blocking entity is always drawn over the pile. otherwise we draw the pile entity with the highest
+	// priority, and for ties the one on top.
 	if entity := tile.GetEntity(); entity.IsValid() {
 		vis, e = entity.GetVisuals(), entity
+	} else if pile := tile.GetPile(); len(pile) > 0 {
+		e = pile[len(pile)-1]
+		for _, entity := range slices.Backward(pile) {
+			if entity.GetEntityData().DrawPriority > e.GetEntityData().DrawPriority {
+				e = entity
+			}
+		}
+		vis = e.GetVisuals()
 	}
 
 	return
@@ -123,6 +153,24 @@ func (t Tile) GetEntity() Entity {
 	}
 }
 
+// GetPile returns the passable entities in the tile, bottom first. Don't modify the returned slice!
+func (t Tile) GetPile() []Entity {
+	if container := ecs.Get[EntityContainerComponent](t); container != nil {
+		return container.Pile
+	} else {
+		return nil
+	}
+}
+
+// Contains reports whether the entity is in the tile, either as the blocking entity or in the pile.
+func (t Tile) Contains(entity Entity) bool {
+	if container := ecs.Get[EntityContainerComponent](t); container != nil {
+		return container.Contains(entity)
+	} else {
+		return false
+	}
+}
+
 func (t Tile) HasEntity() bool {
 	if container := ecs.Get[EntityContainerComponent](t); container != nil {
 		return container.Entity.IsValid()
@@ -19,6 +19,10 @@ type TileMap struct {
 	LightSystem
 	FOVSystem
 	AnimationSystem
+	TurnSystem
+	AISystem
+	HealthSystem
+	StatusEffectSystem
 
 	Ready bool // set this to true once level generation is complete! suppresses events while false.
 
@@ -37,15 +41,7 @@ func (tm *TileMap) getMap() *TileMap {
 // Initialize the TileMap. All tiles in the map will be set to defaultTile. Be sure to call TileMap.Cleanup() before
 // getting rid of a tilemap!
 func (tm *TileMap) Init(size vec.Dims, defaultTile TileType) {
-	tm.DirtyTracker.Init(size)
-	tm.events.Listen(EV_ENTITYBEINGDESTROYED, EV_TILECHANGEDVISIBILITY)
-	tm.events.SetEventHandler(tm.handleEvent)
-	tm.size = size
-	tm.opacityMap.Init(size.Area())
-
-	tm.LightSystem.Init(tm)
-	tm.FOVSystem.Init(tm)
-	tm.AnimationSystem.Init(tm)
+	tm.init(size)
 
 	tm.tiles = make([]Tile, 0, size.Area())
 	for cursor := range vec.EachCoordInArea(tm.Bounds()) {
@@ -57,6 +53,23 @@ func (tm *TileMap) Init(size vec.Dims, defaultTile TileType) {
 	}
 }
 
+// sets up everything except the tiles themselves.
+func (tm *TileMap) init(size vec.Dims) {
+	tm.DirtyTracker.Init(size)
+	tm.events.Listen(EV_ENTITYBEINGDESTROYED, EV_TILECHANGEDVISIBILITY)
+	tm.events.SetEventHandler(tm.handleEvent)
+	tm.size = size
+	tm.opacityMap.Init(size.Area())
+
+	tm.LightSystem.Init(tm)
+	tm.FOVSystem.Init(tm)
+	tm.AnimationSystem.Init(tm)
+	tm.TurnSystem.Init(tm)
+	tm.AISystem.Init(tm)
+	tm.HealthSystem.Init(tm)
+	tm.StatusEffectSystem.Init(tm)
+}
+
 func (tm *TileMap) Cleanup() {
 	for _, tile := range tm.tiles {
 		ecs.DestroyEntity(tile)
@@ -64,6 +77,9 @@ func (tm *TileMap) Cleanup() {
 
 	tm.LightSystem.Shutdown()
 	tm.FOVSystem.Shutdown()
+	tm.AISystem.Shutdown()
+	tm.HealthSystem.Shutdown()
+	tm.StatusEffectSystem.Shutdown()
 	tm.events.DisableListening()
 }
 
@@ -76,6 +92,7 @@ func (tm *TileMap) Update(delta time.Duration) {
 		return
 	}
 
+	tm.TurnSystem.Update(delta)
 	tm.FOVSystem.Update(delta)
 }
 
@@ -85,16 +102,11 @@ func (tm *TileMap) handleEvent(e event.Event) (event_handled bool) {
 		entity := e.(*EntityEvent).Entity
 
 		// ensure entity being destroyed is in the tilemap
-		pos := entity.Position()
-		if !tm.Bounds().Contains(pos) || tm.GetTile(pos).GetEntity() != entity {
+		if !tm.containsEntity(entity) {
 			return
 		}
 
-		if light := ecs.Get[LightSourceComponent](entity); light != nil {
-			tm.removeAppliedLight(light)
-		}
-
-		tm.RemoveEntityAt(pos)
+		tm.removeFromMap(entity)
 	case EV_TILECHANGEDVISIBILITY:
 		o := e.(*TileChangedVisibilityEvent)
 		tm.opacityMap.SetTo(o.Pos.ToIndex(tm.size.W), o.Opaque)
@@ -105,6 +117,21 @@ func (tm *TileMap) handleEvent(e event.Event) (event_handled bool) {
 	return true
 }
 
+// reports whether the entity is in the tilemap.
+func (tm *TileMap) containsEntity(entity Entity) bool {
+	position := ecs.Get[PositionComponent](entity)
+	return position != nil && tm.Bounds().Contains(position.Coord) && tm.GetTile(position.Coord).Contains(entity)
+}
+
+// removes the entity from the tilemap along with any light it was casting.
+func (tm *TileMap) removeFromMap(entity Entity) {
+	if light := ecs.Get[LightSourceComponent](entity); light != nil {
+		tm.removeAppliedLight(light)
+	}
+
+	tm.RemoveEntity(entity)
+}
+
 func (tm TileMap) Size() vec.Dims {
 	return tm.size
 }
@@ -150,6 +177,10 @@ func (tm *TileMap) SetTile(pos vec.Coord, tile Tile) {
 	ecs.DestroyEntity(oldTile)
 	tm.tiles[pos.ToIndex(tm.size.W)] = tile
 	tm.SetDirty(pos)
+
+	if tm.Ready {
+		event.Fire(EV_TILECHANGED, &TileChangedEvent{Pos: pos})
+	}
 }
 
 func (tm *TileMap) SetTileType(pos vec.Coord, tileType TileType) {
@@ -174,29 +205,65 @@ func (tm *TileMap) SetTileType(pos vec.Coord, tileType TileType) {
 
 	tm.tiles[pos.ToIndex(tm.size.W)].SetTileType(tileType)
 	tm.SetDirty(pos)
+
+	if tm.Ready {
+		event.Fire(EV_TILECHANGED, &TileChangedEvent{Pos: pos})
+	}
 }
 
-func (tm *TileMap) AddEntity(entity Entity, pos vec.Coord) {
+// AddEntity puts the entity into the tilemap at pos. Passable entities (see EntityData.Passable) go on top of the pile
+// in the tile, other entities need the tile to be unoccupied. Either way the tile itself must be passable. Returns
+// false if the entity could not be added.
+func (tm *TileMap) AddEntity(entity Entity, pos vec.Coord) bool {
 	if !pos.IsInside(tm) {
-		return
+		return false
 	}
 
 	tile := tm.GetTile(pos)
-	if !ecs.Alive(tile) || !tile.IsPassable() {
-		return
+	if !ecs.Alive(tile) || !tile.GetTileType().Data().Passable {
+		return false
 	}
 
-	if container := ecs.Get[EntityContainerComponent](tile); container != nil && container.Empty() {
+	container := ecs.Get[EntityContainerComponent](tile)
+	if container == nil {
+		return false
+	}
+
+	if entity.IsPassable() {
+		entity.MoveTo(pos)
+		container.AddToPile(entity)
+		tm.fireTileItemEvent(EV_TILEITEMADDED, entity, pos)
+	} else {
+		if !container.Empty() {
+			return false
+		}
+
 		entity.MoveTo(pos)
 		container.Add(entity)
-		tm.SetDirty(pos)
 	}
+
+	tm.SetDirty(pos)
+	return true
 }
 
+// RemoveEntity takes the entity out of the tilemap, whether it is the blocking entity in its tile or in the pile.
 func (tm *TileMap) RemoveEntity(entity Entity) {
-	tm.RemoveEntityAt(entity.Position())
+	pos := entity.Position()
+	if !pos.IsInside(tm) {
+		return
+	}
+
+	if container := ecs.Get[EntityContainerComponent](tm.GetTile(pos)); container != nil && container.RemoveFromPile(entity) {
+		entity.MoveTo(NOT_IN_TILEMAP)
+		tm.SetDirty(pos)
+		tm.fireTileItemEvent(EV_TILEITEMREMOVED, entity, pos)
+		return
+	}
+
+	tm.RemoveEntityAt(pos)
 }
 
+// RemoveEntityAt takes the blocking entity at pos out of the tilemap. Entities in the pile are left alone.
 func (tm *TileMap) RemoveEntityAt(pos vec.Coord) {
 	if !pos.IsInside(tm) {
 		return
@@ -219,6 +286,7 @@ func (tm *TileMap) RemoveEntityAt(pos vec.Coord) {
 	tm.SetDirty(pos)
 }
 
+// GetEntityAt returns the blocking entity at pos, or INVALID_ENTITY if there isn't one.
 func (tm *TileMap) GetEntityAt(pos vec.Coord) Entity {
 	tile := tm.GetTile(pos)
 	if !ecs.Valid(tile) {
@@ -228,19 +296,51 @@ func (tm *TileMap) GetEntityAt(pos vec.Coord) Entity {
 	return tile.GetEntity()
 }
 
-func (tm *TileMap) MoveEntity(entity Entity, to vec.Coord) {
+// GetPileAt returns the passable entities at pos, bottom first. Don't modify the returned slice!
+func (tm *TileMap) GetPileAt(pos vec.Coord) []Entity {
+	tile := tm.GetTile(pos)
+	if !ecs.Valid(tile) {
+		return nil
+	}
+
+	return tile.GetPile()
+}
+
+// MoveEntity moves the entity to a new position. Blocking entities can only move to unoccupied, passable tiles.
+// Passable entities can move to any passable tile, occupied or not, and are put on top of the pile there. Returns
+// false if the entity could not be moved.
+func (tm *TileMap) MoveEntity(entity Entity, to vec.Coord) bool {
 	if !entity.IsValid() {
-		return
+		return false
 	}
 
 	from := entity.Position()
 	if !from.IsInside(tm) || !to.IsInside(tm) {
-		return
+		return false
 	}
 
 	fromTile, toTile := tm.GetTile(from), tm.GetTile(to)
+	if entity.IsPassable() {
+		if !toTile.GetTileType().Data().Passable {
+			return false
+		}
+
+		fromContainer, toContainer := ecs.Get[EntityContainerComponent](fromTile), ecs.Get[EntityContainerComponent](toTile)
+		if fromContainer == nil || toContainer == nil || !fromContainer.RemoveFromPile(entity) {
+			return false
+		}
+
+		toContainer.AddToPile(entity)
+		tm.SetDirty(from)
+		tm.SetDirty(to)
+		entity.MoveTo(to)
+		tm.fireTileItemEvent(EV_TILEITEMREMOVED, entity, from)
+		tm.fireTileItemEvent(EV_TILEITEMADDED, entity, to)
+		return true
+	}
+
 	if fromTile.GetEntity() != entity || !toTile.IsPassable() {
-		return
+		return false
 	}
 
 	ecs.Get[EntityContainerComponent](toTile).Entity = entity
@@ -249,6 +349,13 @@ func (tm *TileMap) MoveEntity(entity Entity, to vec.Coord) {
 	tm.SetDirty(from)
 
 	entity.MoveTo(to)
+	return true
+}
+
+func (tm *TileMap) fireTileItemEvent(id event.EventID, entity Entity, pos vec.Coord) {
+	if tm.Ready {
+		event.Fire(id, &TileItemEvent{Entity: entity, Pos: pos})
+	}
 }
 
 func (tm TileMap) Draw(dst_canvas *gfx.Canvas, offset vec.Coord, depth int) {
@@ -297,7 +404,7 @@ func (tm *TileMap) calcTileVisuals(pos vec.Coord, viewer Entity) (vis gfx.Visual
 		}
 	}
 
-	vis = tm.LightTileVisuals(vis, light)
+	vis = tm.LightTileVisuals(vis, light, tm.GetLightColour(pos))
 
 	return
 }
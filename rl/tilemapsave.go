@@ -0,0 +1,155 @@
+package rl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bennicholls/tyumi/log"
+	"github.com/bennicholls/tyumi/rl/ecs"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// tileMapHeader is written before the tilemap's entities when saving.
+type tileMapHeader struct {
+	Size        vec.Dims
+	Ready       bool
+	GlobalLight uint8
+	Tick        int
+	Tiles       []Tile // saved IDs of the tiles, in tilemap order
+}
+
+// Save writes the tilemap to the writer. This includes the terrain, any entities in the map along with all of their
+// saveable components (light sources, the player's memory, etc.), the items in their inventories, and the tilemap's
+// global light. Tile and entity types are saved by name, so they must be registered with the same names when the map
+// is loaded.
+func (tm *TileMap) Save(w io.Writer) error {
+	header := tileMapHeader{
+		Size:        tm.size,
+		Ready:       tm.Ready,
+		GlobalLight: tm.globalLight,
+		Tick:        tm.Tick,
+		Tiles:       tm.tiles,
+	}
+
+	if err := json.NewEncoder(w).Encode(header); err != nil {
+		log.Error("Could not save tilemap: ", err)
+		return err
+	}
+
+	return ecs.SaveEntities(w, func(yield func(ecs.Entity) bool) {
+		for _, tile := range tm.tiles {
+			if !yield(ecs.Entity(tile)) {
+				return
+			}
+
+			if entity := tile.GetEntity(); entity.IsValid() {
+				if !yieldWithInventory(entity, yield) {
+					return
+				}
+			}
+
+			for _, entity := range tile.GetPile() {
+				if !yieldWithInventory(entity, yield) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// yields the entity, followed by the items in its inventory (and their inventories, and so on).
+func yieldWithInventory(entity Entity, yield func(ecs.Entity) bool) bool {
+	if !yield(ecs.Entity(entity)) {
+		return false
+	}
+
+	if inventory := ecs.Get[InventoryComponent](entity); inventory != nil {
+		for _, item := range inventory.Items {
+			if !yieldWithInventory(item, yield) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Load builds the tilemap from a stream written by Save(). Use this instead of Init() to set up the tilemap. Once
+// loaded, the opacity map is rebuilt and the light and FOV systems are set to recompute everything the next time
+// the tilemap updates. Loaded entities are given new IDs; the returned remap translates saved IDs to the loaded
+// ones. To find the player again you can also just look for the entity with a PlayerComponent. If loading fails, any
+// entities loaded so far are destroyed and the tilemap is left untouched.
+func (tm *TileMap) Load(r io.Reader) (remap ecs.EntityRemap, err error) {
+	if tm.tiles != nil {
+		err = errors.New("tilemap already initialized")
+		log.Error("Could not load tilemap: ", err)
+		return
+	}
+
+	var header tileMapHeader
+	decoder := json.NewDecoder(r)
+	if err = decoder.Decode(&header); err != nil {
+		log.Error("Could not load tilemap: ", err)
+		return
+	}
+
+	if len(header.Tiles) != header.Size.Area() {
+		err = fmt.Errorf("expected %d tiles, found %d", header.Size.Area(), len(header.Tiles))
+		log.Error("Could not load tilemap: ", err)
+		return
+	}
+
+	// the decoder may have read past the header, so we have to include whatever it has buffered
+	// if this fails, ecs.Load destroys everything it loaded, so there's nothing to clean up
+	remap, err = ecs.Load(io.MultiReader(decoder.Buffered(), r))
+	if err != nil {
+		return nil, err
+	}
+
+	// make sure all the tiles are there before touching the tilemap, so a bad save leaves it untouched
+	tiles := make([]Tile, len(header.Tiles))
+	for i, savedTile := range header.Tiles {
+		tile := Tile(remap.Remap(ecs.Entity(savedTile)))
+		if !ecs.Alive(tile) || !ecs.Has[TerrainComponent](tile) {
+			err = fmt.Errorf("tile %d missing from save", i)
+			log.Error("Could not load tilemap: ", err)
+			for _, entity := range remap {
+				ecs.DestroyEntity(entity)
+			}
+			return nil, err
+		}
+
+		tiles[i] = tile
+	}
+
+	tm.init(header.Size)
+	tm.tiles = tiles
+	for i, tile := range tm.tiles {
+		tm.opacityMap.SetTo(i, tile.IsOpaque())
+	}
+
+	tm.SetGlobalLight(header.GlobalLight)
+	tm.Tick = header.Tick
+
+	// lights and fovs need to be recomputed from scratch
+	for _, entity := range remap {
+		if light := ecs.Get[LightSourceComponent](entity); light != nil {
+			light.AreaDirty = true
+			// lights carried in inventories aren't in the map, so they don't light anything
+			if position := ecs.Get[PositionComponent](entity); position != nil && position.Coord.IsInside(tm) && !light.Disabled {
+				tm.LightSystem.sources.Add(position.Coord)
+			}
+		}
+
+		if fov := ecs.Get[FOVComponent](entity); fov != nil {
+			fov.Dirty = true
+		}
+	}
+
+	tm.SetAllDirty()
+	tm.Ready = header.Ready
+
+	return
+}
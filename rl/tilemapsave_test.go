@@ -0,0 +1,168 @@
+package rl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/gfx"
+	"github.com/bennicholls/tyumi/gfx/col"
+	"github.com/bennicholls/tyumi/rl/ecs"
+	"github.com/bennicholls/tyumi/vec"
+)
+
+var (
+	saveTestFloor = RegisterTileType(TileData{Name: "save_test_floor", Passable: true, Visuals: gfx.NewGlyphVisuals(gfx.Glyph('.'), col.Pair{col.WHITE, col.BLACK})})
+	saveTestWall  = RegisterTileType(TileData{Name: "save_test_wall", Opaque: true})
+	saveTestRat   = RegisterEntityType(EntityData{Name: "save_test_rat", HP: 7})
+	saveTestLamp  = RegisterEntityType(EntityData{Name: "save_test_lamp"})
+)
+
+func TestTileMapSaveLoad(t *testing.T) {
+	var tm TileMap
+	tm.Init(vec.Dims{8, 8}, saveTestFloor)
+	tm.SetTileType(vec.Coord{3, 3}, saveTestWall)
+	tm.SetGlobalLight(100)
+	tm.Ready = true
+
+	rat := CreateEntity(saveTestRat)
+	tm.AddEntity(rat, vec.Coord{1, 1})
+	ecs.Get[HealthComponent](rat).HP.Set(4)
+	ecs.Add(rat, MemoryComponent{})
+	ecs.Get[MemoryComponent](rat).AddMemory(&tm, vec.Coord{2, 2})
+
+	flickering := CreateEntity(saveTestLamp)
+	ecs.Add(flickering, LightSourceComponent{Power: 50, FalloffRate: 10, FlickerSpeed: 5})
+	tm.AddEntity(flickering, vec.Coord{5, 5})
+
+	disabled := CreateEntity(saveTestLamp)
+	ecs.Add(disabled, LightSourceComponent{Power: 50, FalloffRate: 10, FlickerSpeed: 5, Disabled: true})
+	tm.AddEntity(disabled, vec.Coord{6, 6})
+
+	carried := CreateEntity(saveTestLamp)
+	ecs.Add(carried, ItemComponent{})
+	ecs.Add(carried, LightSourceComponent{Power: 50, FalloffRate: 10})
+	ecs.Add(rat, InventoryComponent{})
+	ecs.Get[InventoryComponent](rat).add(carried)
+
+	var buf bytes.Buffer
+	if err := tm.Save(&buf); err != nil {
+		t.Fatal("Save failed: ", err)
+	}
+
+	enabledEvents := 0
+	var lightEvents event.Stream
+	lightEvents.Listen(EV_LIGHTENABLED)
+	lightEvents.SetImmediateEventHandler(func(e event.Event) bool {
+		enabledEvents++
+		return true
+	})
+
+	var loaded TileMap
+	remap, err := loaded.Load(&buf)
+	if err != nil {
+		t.Fatal("Load failed: ", err)
+	}
+	lightEvents.DisableListening()
+
+	if loaded.Size() != tm.Size() || !loaded.Ready {
+		t.Errorf("Tilemap header loaded incorrectly: size %v, ready %v", loaded.Size(), loaded.Ready)
+	}
+
+	if loaded.GetTile(vec.Coord{3, 3}).GetTileType() != saveTestWall || !loaded.IsTileOpaque(vec.Coord{3, 3}) {
+		t.Error("Wall tile loaded incorrectly.")
+	}
+
+	if loaded.GetTile(vec.Coord{0, 0}).GetTileType() != saveTestFloor || loaded.IsTileOpaque(vec.Coord{0, 0}) {
+		t.Error("Floor tile loaded incorrectly.")
+	}
+
+	loadedRat := Entity(remap.Remap(ecs.Entity(rat)))
+	if loaded.GetTile(vec.Coord{1, 1}).GetEntity() != loadedRat || loadedRat.GetEntityData().Name != "save_test_rat" {
+		t.Error("Rat loaded incorrectly.")
+	}
+
+	if health := ecs.Get[HealthComponent](loadedRat); health == nil || health.HP.Get() != 4 || health.HP.Max() != 7 {
+		t.Errorf("Rat health loaded incorrectly: %v", health)
+	}
+
+	if memory := ecs.Get[MemoryComponent](loadedRat); memory == nil || !memory.HasMemory(vec.Coord{2, 2}) {
+		t.Error("Rat memory loaded incorrectly.")
+	}
+
+	loadedFlickering := Entity(remap.Remap(ecs.Entity(flickering)))
+	if light := ecs.Get[LightSourceComponent](loadedFlickering); light == nil || light.flickerAnimation == nil || !light.flickerAnimation.IsPlaying() {
+		t.Error("Flickering light lost its flicker.")
+	}
+
+	loadedDisabled := Entity(remap.Remap(ecs.Entity(disabled)))
+	if light := ecs.Get[LightSourceComponent](loadedDisabled); light == nil || !light.Disabled || light.flickerAnimation == nil || light.flickerAnimation.IsPlaying() {
+		t.Error("Disabled light loaded incorrectly.")
+	}
+
+	// the flickering light and the carried light
+	if enabledEvents != 2 {
+		t.Errorf("Loading fired %d light enabled events, wanted 2", enabledEvents)
+	}
+
+	if loaded.LightSystem.sources.Count() != 1 || !loaded.LightSystem.sources.Contains(vec.Coord{5, 5}) {
+		t.Errorf("Light sources loaded incorrectly: %v", loaded.LightSystem.sources)
+	}
+
+	tm.Cleanup()
+	loaded.Cleanup()
+}
+
+func TestTileMapLoadFailure(t *testing.T) {
+	var tm TileMap
+	tm.Init(vec.Dims{2, 2}, saveTestFloor)
+	tm.SetTileType(vec.Coord{1, 1}, saveTestWall)
+	tm.AddEntity(CreateEntity(saveTestRat), vec.Coord{0, 0})
+
+	var buf bytes.Buffer
+	if err := tm.Save(&buf); err != nil {
+		t.Fatal("Save failed: ", err)
+	}
+	saved := buf.Bytes()
+
+	// drop the last entity record (a tile) from the save
+	missingTile := bytes.TrimRight(saved, "\n")
+	missingTile = missingTile[:bytes.LastIndexByte(missingTile, '\n')+1]
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"missing tile", missingTile},
+		{"unregistered tile type", bytes.ReplaceAll(saved, []byte("save_test_wall"), []byte("unregistered_tile"))},
+		{"unregistered entity type", bytes.ReplaceAll(saved, []byte("save_test_rat"), []byte("unregistered_entity"))},
+	}
+
+	for _, test := range tests {
+		before := 0
+		for range ecs.EachAliveEntity() {
+			before++
+		}
+
+		var loaded TileMap
+		if _, err := loaded.Load(bytes.NewReader(test.data)); err == nil {
+			t.Errorf("%s: Load succeeded.", test.name)
+			continue
+		}
+
+		after := 0
+		for range ecs.EachAliveEntity() {
+			after++
+		}
+
+		if after != before {
+			t.Errorf("%s: Failed load leaked %d entities.", test.name, after-before)
+		}
+
+		if loaded.tiles != nil {
+			t.Errorf("%s: Failed load left the tilemap half built.", test.name)
+		}
+	}
+
+	tm.Cleanup()
+}
@@ -0,0 +1,112 @@
+package rl
+
+import (
+	"iter"
+	"math"
+
+	"github.com/bennicholls/tyumi/vec"
+)
+
+// TraceMode determines how lines are traced across the tilemap.
+type TraceMode uint8
+
+const (
+	TRACE_BRESENHAM  TraceMode = iota // thin lines. can slip between diagonally adjacent walls.
+	TRACE_SUPERCOVER                  // visits every space the line touches. won't slip between diagonal walls.
+)
+
+func (mode TraceMode) eachCoord(line vec.Line) iter.Seq[vec.Coord] {
+	if mode == TRACE_SUPERCOVER {
+		return line.EachCoordSupercover()
+	}
+
+	return line.EachCoord()
+}
+
+// Trace is the result of tracing a projectile across the tilemap.
+type Trace struct {
+	Path    []vec.Coord // spaces the projectile passed through, not including the start. the last one is where it stopped.
+	Hit     Entity      // the blocking entity the projectile hit, if any
+	HitWall bool        // true if the projectile was stopped by an opaque tile. the tile is the last space in the path.
+}
+
+// End returns the space where the projectile stopped. If the path is empty (the projectile went nowhere), returns
+// NOT_IN_TILEMAP.
+func (t Trace) End() vec.Coord {
+	if len(t.Path) == 0 {
+		return NOT_IN_TILEMAP
+	}
+
+	return t.Path[len(t.Path)-1]
+}
+
+// HasLineOfSight reports whether there are no opaque tiles on the line between from and to. The endpoints themselves
+// are not checked, so you can have line of sight to a wall. Bresenham lines aren't symmetric, so the line is checked in
+// both directions and if either is clear we have line of sight. This way if a can see b, b can see a.
+func (tm *TileMap) HasLineOfSight(from, to vec.Coord) bool {
+	if !from.IsInside(tm) || !to.IsInside(tm) {
+		return false
+	}
+
+	return tm.lineIsClear(vec.Line{from, to}) || tm.lineIsClear(vec.Line{to, from})
+}
+
+func (tm *TileMap) lineIsClear(line vec.Line) bool {
+	for pos := range line.EachCoord() {
+		if pos == line.Start || pos == line.End {
+			continue
+		}
+
+		if tm.IsTileOpaque(pos) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TraceProjectile traces the path of a projectile fired from one space toward another. The projectile stops at the
+// first opaque tile or blocking entity it runs into, or at the edge of the map. Entities in the starting space (the
+// thrower, probably) are ignored. Passable entities (items and such) never stop projectiles.
+//
+// If max_range is provided, the projectile doesn't stop at the target: it keeps flying along the same line until it
+// has travelled max_range spaces or hits something. Otherwise it stops at the target.
+func (tm *TileMap) TraceProjectile(from, to vec.Coord, mode TraceMode, max_range ...int) (trace Trace) {
+	if from == to || !from.IsInside(tm) {
+		return
+	}
+
+	line := vec.Line{from, to}
+	rangeSq := -1
+	if len(max_range) > 0 && max_range[0] > 0 {
+		// extend the line far enough that it reaches the full range
+		r := max_range[0]
+		rangeSq = r * r
+		scale := int(math.Ceil(float64(r) / line.Length()))
+		line.End = from.Add(to.Subtract(from).Scale(scale))
+	}
+
+	for pos := range mode.eachCoord(line) {
+		if pos == from {
+			continue
+		}
+
+		if !pos.IsInside(tm) || (rangeSq >= 0 && from.DistanceSqTo(pos) > rangeSq) {
+			break
+		}
+
+		trace.Path = append(trace.Path, pos)
+
+		if tm.IsTileOpaque(pos) {
+			trace.HitWall = true
+			break
+		}
+
+		if entity := tm.GetTile(pos).GetEntity(); entity.IsValid() {
+			trace.Hit = entity
+			break
+		}
+	}
+
+	return
+}
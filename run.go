@@ -17,6 +17,7 @@ import (
 
 var (
 	running  bool
+	started  bool // true once the engine has been started by Run() or Step()
 	renderer Renderer
 	events   event.Stream //the main event stream for engine-level events
 )
@@ -27,11 +28,62 @@ func Run() {
 		defer profile.Start(profile.CPUProfile, profile.ProfilePath(".")).Stop()
 	}
 
-	if !isInitialized() {
+	if !start() {
 		log.Error("Tyumi must shut down now. Bye Bye.")
 		return
 	}
 
+	for running {
+		delta := fixedFrameDelta
+		if delta == 0 {
+			delta = time.Since(frameStartTime)
+		}
+
+		runFrame(delta)
+		limitFramerate() //sleep to maintain framerate if necessary
+	}
+
+	shutdown()
+}
+
+// Step runs a single frame of the gameloop, without any framerate limiting. The provided delta is used as the
+// duration of the frame, and is what GetFrameDelta() will report while the frame runs. Use this instead of Run() to
+// drive the engine yourself, for example to test a scene frame by frame or to run a simulation faster than real time.
+//
+// The first call to Step() prepares the engine to run, so everything required by Run() (platform, console, initial
+// scene) must be set up beforehand. Once the engine quits, the platform is shut down and further calls to Step() do
+// nothing. Use IsRunning() to check for this.
+func Step(delta time.Duration) {
+	if !running {
+		if started {
+			return
+		}
+
+		if !start() {
+			log.Error("Could not step engine: Tyumi not initialized.")
+			return
+		}
+	}
+
+	runFrame(delta)
+
+	if !running {
+		shutdown()
+	}
+}
+
+// IsRunning returns true if the gameloop is running, i.e. the engine has been started with Run() or Step() and has
+// not quit.
+func IsRunning() bool {
+	return running
+}
+
+// prepares the engine to start running frames. returns false if the engine can't be started.
+func start() bool {
+	if !isInitialized() {
+		return false
+	}
+
 	events = event.NewStream(250, handleEvent)
 	events.Listen(EV_QUIT, EV_CHANGESCENE)
 	if Debug {
@@ -42,34 +94,44 @@ func Run() {
 		fpsLabelUpdateTime = time.Now()
 	}
 
-	currentFrameTime = time.Now() // so we get a non-nonsensical frame delta for the first frame.
+	frameStartTime = time.Now()
+	currentFrameTime = frameStartTime // so we get a non-nonsensical frame delta for the first frame.
+	started = true
+	running = true
 
-	for running = true; running; {
-		beginFrame()
-		currentPlatform.GenerateEvents() //take inputs from platform, convert to tyumi events as appropriate, and distribute
-		update()                         //step forward the gamestate
-		updateUI()                       //update changed UI elements
-		render()                         //composite frame together, post process, and render to screen
-		events.ProcessEvents()           //processes internal events
-		endFrame()                       //do any end of tick cleanup, then sleep to maintain framerate if necessary
-	}
+	return true
+}
+
+// runs one full cycle of the gameloop. delta is the simulated duration of the frame.
+func runFrame(delta time.Duration) {
+	beginFrame(delta)
+	generateEvents()       //take inputs from platform (or a replay), convert to tyumi events as appropriate, and distribute
+	update()               //step forward the gamestate
+	updateUI()             //update changed UI elements
+	render()               //composite frame together, post process, and render to screen
+	events.ProcessEvents() //processes internal events
+	endFrame()             //do any end of tick cleanup
+}
 
+func shutdown() {
 	currentPlatform.Shutdown()
 	log.Info("Tyumi says goodbye. ;)")
 }
 
-func beginFrame() {
+func beginFrame(delta time.Duration) {
+	frameStartTime = time.Now()
 	prevFrameTime = currentFrameTime
-	currentFrameTime = time.Now()
+	currentFrameTime = currentFrameTime.Add(delta)
 }
 
 // This is the generic tick function. Steps forward the gamestate, and performs some engine-specific per-tick functions.
 func update() {
 	mainConsole.ProcessEvents()
 
-	updateScene(currentScene)
+	layer := topLayer()
+	updateScene(layer.scene)
 
-	for _, d := range slices.Backward(dialogs) {
+	for _, d := range slices.Backward(layer.dialogs) {
 		if !d.IsDone() {
 			updateScene(d)
 		} else {
@@ -81,10 +143,11 @@ func update() {
 func updateScene(s scene) {
 	if !s.IsBlocked() {
 		s.InputEvents().ProcessEvents()
-		s.processTimers()
+		s.processTimers(GetFrameDelta(), false)
 		s.Update(GetFrameDelta())
 	} else {
 		s.flushInputs()
+		s.processTimers(GetFrameDelta(), true)
 	}
 
 	s.ProcessEvents() //process any gameplay events from this frame.
@@ -92,11 +155,17 @@ func updateScene(s scene) {
 
 // Updates any UI elements that need updating after the most recent tick in the current active scene.
 func updateUI() {
-	currentScene.Window().Update(GetFrameDelta())
+	layer := topLayer()
+	layer.scene.Window().Update(GetFrameDelta())
 
-	for _, d := range dialogs {
+	for _, d := range layer.dialogs {
 		d.Window().Update(GetFrameDelta())
 	}
+
+	// keep animating the outgoing scene of a transition until it's done
+	if outgoingScene != nil {
+		outgoingScene.Window().Update(GetFrameDelta())
+	}
 }
 
 // builds the frame and renders using the current platform's renderer.
@@ -123,29 +192,31 @@ func render() {
 }
 
 func endFrame() {
-	//framerate limiter, so the cpu doesn't implode
-	if !overclock {
-		sleep := frameTargetDuration - time.Since(currentFrameTime)
-		sleepTime += sleep
-		time.Sleep(sleep)
-	}
-
 	ecs.ProcessQueuedEntities()
 
 	tick++
 }
 
+// framerate limiter, so the cpu doesn't implode
+func limitFramerate() {
+	if overclock {
+		return
+	}
+
+	sleep := frameTargetDuration - time.Since(frameStartTime)
+	sleepTime += sleep
+	time.Sleep(sleep)
+}
+
 // handles events from the engine's internal event stream. runs once per tick
 func handleEvent(e event.Event) (event_handled bool) {
 	switch e.ID() {
 	case EV_QUIT: //quit event, like from clicking the close window button on the window
-		closeAllDialogs()
-		currentScene.Shutdown()
-		currentScene.cleanup()
+		shutdownAllScenes()
 		running = false
 		event_handled = true
 	case EV_CHANGESCENE:
-		changeScene(e.(*SceneChangeEvent).newScene)
+		changeScene(e.(*SceneChangeEvent))
 		event_handled = true
 	}
 
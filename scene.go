@@ -28,7 +28,7 @@ type scene interface {
 	Shutdown()
 
 	Update(delta time.Duration)
-	processTimers()
+	processTimers(delta time.Duration, blocked bool)
 	IsBlocked() bool
 
 	flushInputs()
@@ -45,12 +45,14 @@ type Scene struct {
 
 	window *ui.Window
 
-	timers []Timer
+	timers []*Timer
 
 	inputEvents          event.Stream        //for input events. processed at the start of each tick
 	inputHandler         event.Handler       //user-provided input handling function. runs AFTER the UI has had a chance to process input.
 	actionHandler        input.ActionHandler //user-provided action handling function. runs AFTER the UI has had a chance to process input.
 	keypressInputHandler func(key_event *input.KeyboardEvent) bool
+	mouseButtonHandler   func(mouse_event *input.MouseButtonEvent) bool
+	mouseWheelHandler    func(wheel_event *input.MouseWheelEvent) bool
 
 	ready bool // indicates the scene has been successfully initialized
 }
@@ -125,13 +127,13 @@ func (s *Scene) init(size vec.Dims, pos vec.Coord, bordered bool) {
 	s.inputEvents = event.NewStream(100, s.handleInput)
 
 	//setup automatic listening for input events.
-	s.inputEvents.Listen(input.EV_ACTION, input.EV_KEYBOARD, input.EV_MOUSEBUTTON, input.EV_MOUSEMOVE)
+	s.inputEvents.Listen(input.EV_ACTION, input.EV_KEYBOARD, input.EV_MOUSEBUTTON, input.EV_MOUSEMOVE, input.EV_MOUSEWHEEL)
 
 	//disable listening so initialized scenes don't accrue events until they are active.
 	s.DisableListening()
 	s.inputEvents.DisableListening()
 
-	s.timers = make([]Timer, 0)
+	s.timers = make([]*Timer, 0)
 	s.ready = true
 }
 
@@ -193,6 +195,24 @@ func (s *Scene) SetActionHandler(action_handler input.ActionHandler) {
 	s.inputEvents.FlushEvents()
 }
 
+// Sets the function for handling mouse button events (both presses and releases). Inputs are collected, distributed
+// and then processed at the beginning of each tick(). This handler is called after the UI has had a chance to handle
+// the event, whether or not the UI handled it. Note that event.Handled() is only set once all handlers have run, so it
+// can't be used here to tell if the UI handled the click.
+// Setting this handler clears the input event stream of any remaining events.
+func (s *Scene) SetMouseButtonHandler(mouse_button_handler func(mouse_event *input.MouseButtonEvent) bool) {
+	s.mouseButtonHandler = mouse_button_handler
+	s.inputEvents.FlushEvents()
+}
+
+// Sets the function for handling mouse wheel events. Inputs are collected, distributed and then processed at the
+// beginning of each tick().
+// Setting this handler clears the input event stream of any remaining events.
+func (s *Scene) SetMouseWheelHandler(mouse_wheel_handler func(wheel_event *input.MouseWheelEvent) bool) {
+	s.mouseWheelHandler = mouse_wheel_handler
+	s.inputEvents.FlushEvents()
+}
+
 func (s *Scene) handleInput(event event.Event) (event_handled bool) {
 	switch event.ID() {
 	case input.EV_ACTION:
@@ -207,6 +227,18 @@ func (s *Scene) handleInput(event event.Event) (event_handled bool) {
 		if s.keypressInputHandler != nil && key_event.PressType == input.KEY_PRESSED {
 			event_handled = s.keypressInputHandler(key_event) || event_handled
 		}
+	case input.EV_MOUSEBUTTON:
+		mouse_event := event.(*input.MouseButtonEvent)
+		event_handled = s.window.HandleMouseButton(mouse_event)
+		if s.mouseButtonHandler != nil {
+			event_handled = s.mouseButtonHandler(mouse_event) || event_handled
+		}
+	case input.EV_MOUSEMOVE:
+		s.window.HandleMouseMove(event.(*input.MouseMoveEvent).Position)
+	case input.EV_MOUSEWHEEL:
+		if s.mouseWheelHandler != nil {
+			event_handled = s.mouseWheelHandler(event.(*input.MouseWheelEvent))
+		}
 	}
 
 	if s.inputHandler != nil {
@@ -230,25 +262,57 @@ func (s *Scene) IsBlocked() bool {
 	return s.window.IsBlocked()
 }
 
-// CreateTimer creates a timer. After duration ticks, the function f is run and the timer is destroyed.
-func (s *Scene) CreateTimer(duration int, f func()) {
+// CreateTimer creates a timer. After duration ticks, the function f is run and the timer is destroyed. Returns the
+// timer, which can be used to pause, resume, or cancel it.
+func (s *Scene) CreateTimer(duration int, f func()) *Timer {
 	if f == nil || duration <= 0 {
-		return
+		return nil
+	}
+
+	return s.addTimer(&Timer{TimerFunction: f, Ticks: duration})
+}
+
+// CreateDurationTimer creates a timer that counts time instead of ticks. After the duration has passed, the function
+// f is run and the timer is destroyed. Time is measured using the frame delta, so timers are unaffected by changes to
+// the framerate. Returns the timer, which can be used to pause, resume, or cancel it.
+func (s *Scene) CreateDurationTimer(duration time.Duration, f func()) *Timer {
+	if f == nil || duration <= 0 {
+		return nil
+	}
+
+	return s.addTimer(&Timer{TimerFunction: f, Duration: duration})
+}
+
+// CreateRepeatingTimer creates a timer that runs the function f every interval until it is cancelled. Returns the
+// timer, which can be used to pause, resume, or cancel it.
+func (s *Scene) CreateRepeatingTimer(interval time.Duration, f func()) *Timer {
+	if f == nil || interval <= 0 {
+		return nil
 	}
 
-	s.timers = append(s.timers, Timer{TimerFunction: f, Ticks: duration})
+	return s.addTimer(&Timer{TimerFunction: f, Duration: interval, Repeat: true})
+}
+
+func (s *Scene) addTimer(timer *Timer) *Timer {
+	s.timers = append(s.timers, timer)
+	return timer
 }
 
-func (s *Scene) processTimers() {
+// processes the scene's timers. if the scene is blocked, only timers set to RunWhileBlocked are processed.
+func (s *Scene) processTimers(delta time.Duration, blocked bool) {
 	if len(s.timers) == 0 {
 		return
 	}
 
 	for i := range s.timers {
-		s.timers[i].Process()
+		if blocked && !s.timers[i].RunWhileBlocked {
+			continue
+		}
+
+		s.timers[i].Update(delta)
 	}
 
-	s.timers = slices.DeleteFunc(s.timers, func(timer Timer) bool {
+	s.timers = slices.DeleteFunc(s.timers, func(timer *Timer) bool {
 		return timer.Done()
 	})
 }
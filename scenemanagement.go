@@ -2,19 +2,57 @@ package tyumi
 
 import (
 	"slices"
+	"time"
 
 	"github.com/bennicholls/tyumi/event"
+	"github.com/bennicholls/tyumi/gfx"
+	"github.com/bennicholls/tyumi/gfx/col"
+	"github.com/bennicholls/tyumi/gfx/ui"
 	"github.com/bennicholls/tyumi/log"
 	"github.com/bennicholls/tyumi/util"
+	"github.com/bennicholls/tyumi/vec"
 )
 
-var currentScene scene
-var dialogs []dialog
+// sceneLayer is an entry in the scene stack: a scene along with any dialogs that have been opened over it.
+type sceneLayer struct {
+	scene   scene
+	dialogs []dialog
+}
+
+// The scene stack. The top layer is the active one, being updated and receiving input. Layers below it are suspended:
+// their windows are hidden and they receive no events, but they are otherwise left untouched so they can resume where
+// they left off when the layers above them are popped.
+var sceneStack []*sceneLayer
+
+var (
+	outgoingScene         scene               // scene being transitioned away from. its window is updated and drawn until the transition completes.
+	outgoingShutdown      bool                // if true, the outgoing scene is shutdown when the transition completes
+	sceneTransitionActive bool                // true while a transition animation is playing
+	pendingSceneChanges   []*SceneChangeEvent // scene changes requested during a transition, applied once it completes
+)
+
+// returns the active layer of the scene stack, or nil if no scene has been set.
+func topLayer() *sceneLayer {
+	if len(sceneStack) == 0 {
+		return nil
+	}
+
+	return sceneStack[len(sceneStack)-1]
+}
+
+// returns the active scene, or nil if no scene has been set.
+func currentScene() scene {
+	if layer := topLayer(); layer != nil {
+		return layer.scene
+	}
+
+	return nil
+}
 
 // SetInitialScene sets a scene to be run by Tyumi at the beginning of execution.
 // This function DOES NOTHING if a scene has already been initialized.
 func SetInitialScene(s scene) {
-	if currentScene != nil {
+	if len(sceneStack) != 0 {
 		return
 	}
 
@@ -28,23 +66,100 @@ func SetInitialScene(s scene) {
 		return
 	}
 
-	currentScene = s
-	currentScene.EnableListening()
-	currentScene.InputEvents().EnableListening()
-	mainConsole.AddChild(s.Window())
-	s.Window().Show()
+	sceneStack = append(sceneStack, &sceneLayer{scene: s})
+	activateScene(s)
 }
 
+// TransitionStyle describes the animation used when moving between scenes.
+type TransitionStyle int
+
+const (
+	TRANSITION_NONE        TransitionStyle = iota
+	TRANSITION_FADE                        // outgoing scene fades out to the transition colour, then the incoming scene fades in from it
+	TRANSITION_SLIDE_LEFT                  // scenes slide towards the left side of the console
+	TRANSITION_SLIDE_RIGHT                 // scenes slide towards the right side of the console
+	TRANSITION_SLIDE_UP                    // scenes slide towards the top of the console
+	TRANSITION_SLIDE_DOWN                  // scenes slide towards the bottom of the console
+)
+
+const DEFAULT_TRANSITION_DURATION time.Duration = 300 * time.Millisecond
+
+// SceneTransition describes an optional animation played when changing scenes. For slides, pushed and replacement
+// scenes slide in over the old scene, and popped scenes slide out to reveal the scene beneath. Scenes are blocked while
+// their part of a transition plays, so they do not update or receive input until it completes.
+type SceneTransition struct {
+	Style    TransitionStyle
+	Duration time.Duration // total duration of the transition. if zero, DEFAULT_TRANSITION_DURATION is used.
+	Colour   col.Colour    // colour to fade through for TRANSITION_FADE. defaults to black.
+}
+
+func (st SceneTransition) duration() time.Duration {
+	if st.Duration <= 0 {
+		return DEFAULT_TRANSITION_DURATION
+	}
+
+	return st.Duration
+}
+
+func (st SceneTransition) colour() col.Colour {
+	if st.Colour == col.NONE {
+		return col.BLACK
+	}
+
+	return st.Colour
+}
+
+// returns the offset of a scene that has been slid fully off of the console.
+func (st SceneTransition) slideOffset() (offset vec.Coord) {
+	size := mainConsole.Size()
+	switch st.Style {
+	case TRANSITION_SLIDE_LEFT:
+		offset.X = -size.W
+	case TRANSITION_SLIDE_RIGHT:
+		offset.X = size.W
+	case TRANSITION_SLIDE_UP:
+		offset.Y = -size.H
+	case TRANSITION_SLIDE_DOWN:
+		offset.Y = size.H
+	}
+
+	return
+}
+
+func (st SceneTransition) isSlide() bool {
+	return st.Style >= TRANSITION_SLIDE_LEFT && st.Style <= TRANSITION_SLIDE_DOWN
+}
+
+type sceneOperation int
+
+const (
+	scene_replace sceneOperation = iota
+	scene_push
+	scene_pop
+	scene_transition_done
+)
+
 type SceneChangeEvent struct {
 	event.EventPrototype
 
-	newScene scene
+	newScene   scene
+	operation  sceneOperation
+	transition SceneTransition
+	fadedOut   bool // for fade transitions, true once the outgoing scene has faded out
 }
 
 // ChangeScene changes the current scene being run in Tyumi's gameloop. The change is done at the end of the current
-// engine tick. The old scene's Shutdown() method is called before we swap in the new one. Be sure to initialize the
-// new scene before calling ChangeScene(), otherwise no change will happen and the old scene will remain.
+// engine tick: the old scene's dialogs are closed, the new scene is swapped in and activated, and then the old scene's
+// Shutdown() method is called. Be sure to initialize the new scene before calling ChangeScene(), otherwise no change
+// will happen and the old scene will remain. Equivalent to ReplaceScene() without a transition.
 func ChangeScene(new_scene scene) {
+	ReplaceScene(new_scene)
+}
+
+// ReplaceScene replaces the scene at the top of the scene stack with new_scene. The change is done at the end of the
+// current engine tick, and the old scene's Shutdown() method is called once it has been swapped out. Optionally takes
+// a transition to animate the change.
+func ReplaceScene(new_scene scene, transition ...SceneTransition) {
 	if new_scene == nil || !new_scene.Ready() {
 		log.Error("Could not change scene: scene invalid or not initialized.")
 		return
@@ -52,28 +167,260 @@ func ChangeScene(new_scene scene) {
 
 	//if user tries to use this to setup the initial scene, just forgive them their sin and do it. no need to
 	//harass them with "the correct way".
-	if currentScene == nil {
+	if len(sceneStack) == 0 {
 		SetInitialScene(new_scene)
 		return
 	}
 
-	event.Fire(EV_CHANGESCENE, &SceneChangeEvent{newScene: new_scene})
+	fireSceneChange(scene_replace, new_scene, transition)
+}
+
+// PushScene suspends the current scene and makes new_scene the active scene. The suspended scene is NOT shutdown; it
+// stops updating and receiving events, and resumes with its state intact when new_scene is popped with PopScene().
+// Any dialogs open in the suspended scene are hidden and restored along with it. The change is done at the end of the
+// current engine tick. Optionally takes a transition to animate the change.
+func PushScene(new_scene scene, transition ...SceneTransition) {
+	if new_scene == nil || !new_scene.Ready() {
+		log.Error("Could not push scene: scene invalid or not initialized.")
+		return
+	}
+
+	if len(sceneStack) == 0 {
+		SetInitialScene(new_scene)
+		return
+	}
+
+	fireSceneChange(scene_push, new_scene, transition)
+}
+
+// PopScene shuts down the current scene and resumes the scene beneath it on the scene stack. The change is done at
+// the end of the current engine tick. The bottom scene of the stack cannot be popped; use ReplaceScene() to change
+// it. Optionally takes a transition to animate the change.
+func PopScene(transition ...SceneTransition) {
+	if len(sceneStack) < 2 {
+		log.Error("Could not pop scene: no scene beneath the current one to return to.")
+		return
+	}
+
+	fireSceneChange(scene_pop, nil, transition)
+}
+
+// SceneStackDepth returns the number of scenes on the scene stack.
+func SceneStackDepth() int {
+	return len(sceneStack)
+}
+
+func fireSceneChange(operation sceneOperation, new_scene scene, transition []SceneTransition) {
+	sce := SceneChangeEvent{newScene: new_scene, operation: operation}
+	if len(transition) > 0 {
+		sce.transition = transition[0]
+	}
+
+	event.Fire(EV_CHANGESCENE, &sce)
 }
 
 // internal scene change function that actually does the work. this is called from the engine's event handler at the
-// end of the tick where a user calls ChangeScene
-func changeScene(new_scene scene) {
-	closeAllDialogs()
+// end of the tick where a user requests a scene change.
+func changeScene(sce *SceneChangeEvent) {
+	if sce.operation == scene_transition_done {
+		finishSceneTransition()
+		return
+	}
+
+	// changes requested while a transition is playing wait until it is finished.
+	if sceneTransitionActive && !sce.fadedOut {
+		pendingSceneChanges = append(pendingSceneChanges, sce)
+		return
+	}
+
+	if sce.operation == scene_pop && len(sceneStack) < 2 {
+		log.Error("Could not pop scene: no scene beneath the current one to return to.")
+		return
+	}
+
+	if sce.transition.Style == TRANSITION_FADE && !sce.fadedOut {
+		fadeOutScene(sce)
+		return
+	}
+
+	oldLayer := topLayer()
+	switch sce.operation {
+	case scene_replace:
+		closeAllDialogs()
+		sceneStack[len(sceneStack)-1] = &sceneLayer{scene: sce.newScene}
+	case scene_push:
+		suspendLayer(oldLayer)
+		sceneStack = append(sceneStack, &sceneLayer{scene: sce.newScene})
+	case scene_pop:
+		closeAllDialogs()
+		sceneStack = sceneStack[:len(sceneStack)-1]
+	}
+
+	activateScene(currentScene())
+	if sce.operation == scene_pop {
+		resumeLayer(topLayer())
+	}
 
-	currentScene.Shutdown()
-	currentScene.cleanup()
-	mainConsole.RemoveChild(currentScene.Window())
+	if sce.transition.isSlide() {
+		slideScenes(sce, oldLayer.scene)
+		return
+	}
+
+	if sce.operation != scene_push {
+		shutdownScene(oldLayer.scene)
+	} else {
+		oldLayer.scene.Window().Hide()
+	}
 
-	currentScene = new_scene
-	mainConsole.AddChild(currentScene.Window())
-	currentScene.Window().Show()
-	currentScene.EnableListening()
-	currentScene.InputEvents().EnableListening()
+	if sce.fadedOut {
+		finishSceneTransition()
+	}
+}
+
+// starts the first half of a fade transition. once the outgoing scene has faded out, the incoming scene is shown and
+// fades in while the scene change is completed at the end of the tick.
+func fadeOutScene(sce *SceneChangeEvent) {
+	outgoing := currentScene()
+	incoming := sce.newScene
+	if sce.operation == scene_pop {
+		incoming = sceneStack[len(sceneStack)-2].scene
+	}
+
+	duration := sce.transition.duration() / 2
+	fadeOut := gfx.NewFadeOutAnimation(vec.Rect{}, ui.BorderDepth+1, duration, sce.transition.colour())
+	fadeOut.Blocking = true
+	fadeOut.OnDone = func() {
+		outgoing.Window().Hide()
+
+		fadeIn := gfx.NewFadeInAnimation(vec.Rect{}, ui.BorderDepth+1, duration, sce.transition.colour())
+		fadeIn.Blocking = true
+		showSceneWindow(incoming)
+		incoming.Window().AddOneShotAnimation(&fadeIn)
+
+		sce.fadedOut = true
+		event.Fire(EV_CHANGESCENE, sce)
+	}
+
+	sceneTransitionActive = true
+	outgoing.Window().AddOneShotAnimation(&fadeOut)
+}
+
+// slides the incoming scene in over the outgoing one, or for pops slides the outgoing scene away to reveal the
+// resumed scene beneath. the outgoing scene is dealt with once the slide is finished.
+func slideScenes(sce *SceneChangeEvent, outgoing scene) {
+	outgoingScene = outgoing
+	outgoingShutdown = sce.operation != scene_push
+	sceneTransitionActive = true
+
+	var slide ui.ElementMoveAnimation
+	var slidingWindow *ui.Window
+	if sce.operation == scene_pop {
+		slidingWindow = outgoing.Window()
+		pos := slidingWindow.Bounds().Coord
+		slide = ui.NewElementMoveAnimation(pos, pos.Add(sce.transition.slideOffset()), sce.transition.duration())
+	} else {
+		slidingWindow = currentScene().Window()
+		pos := slidingWindow.Bounds().Coord
+		slide = ui.NewElementMoveAnimation(pos.Subtract(sce.transition.slideOffset()), pos, sce.transition.duration())
+	}
+
+	// draw the sliding window above the other one (and any of its dialogs) for the duration of the slide
+	slidingWindow.SetDepth(len(topLayer().dialogs) + 1)
+	slide.Blocking = true
+	slide.OnDone = func() {
+		slidingWindow.SetDepth(0)
+		event.Fire(EV_CHANGESCENE, &SceneChangeEvent{operation: scene_transition_done})
+	}
+
+	slide.ApplyToElement(slidingWindow)
+	slidingWindow.AddOneShotAnimation(&slide)
+}
+
+// cleans up after a slide transition, then applies any scene changes that were requested while it was playing.
+func finishSceneTransition() {
+	if outgoingScene != nil {
+		if outgoingShutdown {
+			shutdownScene(outgoingScene)
+		} else {
+			outgoingScene.Window().Hide()
+		}
+
+		outgoingScene = nil
+	}
+
+	sceneTransitionActive = false
+
+	pending := pendingSceneChanges
+	pendingSceneChanges = nil
+	for i, sce := range pending {
+		changeScene(sce)
+		if sceneTransitionActive { // another transition started, leave the rest for when it finishes
+			pendingSceneChanges = append(pending[i+1:], pendingSceneChanges...)
+			return
+		}
+	}
+}
+
+// makes a scene the active one, showing its window and enabling its event streams.
+func activateScene(s scene) {
+	showSceneWindow(s)
+	s.EnableListening()
+	s.InputEvents().EnableListening()
+}
+
+func showSceneWindow(s scene) {
+	if s.Window().GetParent() == nil {
+		mainConsole.AddChild(s.Window())
+	}
+
+	s.Window().Show()
+}
+
+// suspends a layer that is being pushed down the scene stack. its scene keeps its window in the console (hidden once
+// any transition is done) and stops receiving events. any open dialogs are hidden.
+func suspendLayer(layer *sceneLayer) {
+	layer.scene.DisableListening()
+	layer.scene.InputEvents().DisableListening()
+
+	for _, d := range layer.dialogs {
+		d.DisableListening()
+		d.InputEvents().DisableListening()
+		d.Window().Hide()
+	}
+}
+
+// resumes a layer that has returned to the top of the scene stack, restoring any dialogs that were open over it.
+func resumeLayer(layer *sceneLayer) {
+	if len(layer.dialogs) == 0 {
+		return
+	}
+
+	// only the top-most dialog receives input
+	layer.scene.InputEvents().DisableListening()
+	for _, d := range layer.dialogs {
+		d.Window().Show()
+		d.EnableListening()
+	}
+	layer.dialogs[len(layer.dialogs)-1].InputEvents().EnableListening()
+}
+
+func shutdownScene(s scene) {
+	s.Shutdown()
+	s.cleanup()
+}
+
+// shuts down every scene on the stack, top to bottom, along with any open dialogs. called when Tyumi quits.
+func shutdownAllScenes() {
+	if outgoingScene != nil && outgoingShutdown {
+		shutdownScene(outgoingScene)
+		outgoingScene = nil
+	}
+
+	for len(sceneStack) > 0 {
+		closeAllDialogs()
+		shutdownScene(currentScene())
+		sceneStack = sceneStack[:len(sceneStack)-1]
+	}
 }
 
 // Opens a dialog in the current scene.
@@ -83,7 +430,13 @@ func OpenDialog(d dialog) {
 		return
 	}
 
-	if slices.Contains(dialogs, d) {
+	layer := topLayer()
+	if layer == nil {
+		log.Error("Could not open dialog: no scene to open it in.")
+		return
+	}
+
+	if slices.Contains(layer.dialogs, d) {
 		log.Error("Could not open dialog: dialog already open!")
 		return
 	}
@@ -91,34 +444,35 @@ func OpenDialog(d dialog) {
 	d.open()
 
 	// disable input events for the active scene/dialog
-	if len(dialogs) == 0 {
-		currentScene.InputEvents().DisableListening()
+	if len(layer.dialogs) == 0 {
+		layer.scene.InputEvents().DisableListening()
 	} else {
-		dialogs[len(dialogs)-1].InputEvents().DisableListening()
+		layer.dialogs[len(layer.dialogs)-1].InputEvents().DisableListening()
 	}
 
-	d.Window().SetDepth(len(dialogs) + 1)
+	d.Window().SetDepth(len(layer.dialogs) + 1)
 	mainConsole.AddChild(d.Window())
 
 	d.EnableListening()
 	d.InputEvents().EnableListening()
 
-	dialogs = append(dialogs, d)
+	layer.dialogs = append(layer.dialogs, d)
 }
 
 func closeDialog(d dialog) {
-	idx := slices.Index(dialogs, d)
+	layer := topLayer()
+	idx := slices.Index(layer.dialogs, d)
 	if idx == -1 || !d.Ready() {
 		log.Error("Cannot close dialog, dialog not open.")
 		return
 	}
 
 	// if the dialog being closed is the top-most dialog, re-enable inputs for the next dialog/scene down in the hierarchy.
-	if idx == len(dialogs)-1 {
+	if idx == len(layer.dialogs)-1 {
 		if idx == 0 {
-			currentScene.InputEvents().EnableListening()
+			layer.scene.InputEvents().EnableListening()
 		} else {
-			dialogs[idx-1].InputEvents().EnableListening()
+			layer.dialogs[idx-1].InputEvents().EnableListening()
 		}
 	}
 
@@ -133,11 +487,16 @@ func closeDialog(d dialog) {
 		d.InputEvents().DisableListening()
 	}
 
-	dialogs = util.DeleteElement(dialogs, d)
+	layer.dialogs = util.DeleteElement(layer.dialogs, d)
 }
 
 func closeAllDialogs() {
-	for _, d := range slices.Backward(dialogs) {
+	layer := topLayer()
+	if layer == nil {
+		return
+	}
+
+	for _, d := range slices.Backward(layer.dialogs) {
 		closeDialog(d)
 	}
 }
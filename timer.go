@@ -1,32 +1,98 @@
 package tyumi
 
-// Timers allow you to run a function after a certain duration has passed. Call Process() on the timer to tick it down.
-// Once the duration has lapsed the function will be run and it will be done; subsequent calls to Process() will do
-// nothing. Use Done() to check for when the timer can be safely disposed of.
+import (
+	"time"
+)
+
+// Timers allow you to run a function after a certain duration has passed. Timers can count either ticks or time: if
+// Duration is set the timer counts time, otherwise it counts Ticks. Call Update() on the timer to move it forward.
+// Once the duration has lapsed the function will be run and it will be done (unless it repeats); subsequent calls to
+// Update() will do nothing. Use Done() to check for when the timer can be safely disposed of.
 //
-// You can use CreateTimer() on a scene object to make timers that are automatically managed and deleted.
+// You can use CreateTimer() and friends on a scene object to make timers that are automatically managed and deleted.
+// These return a pointer to the timer, which can be used to pause, resume or cancel it.
 type Timer struct {
-	TimerFunction func()
-	Ticks         int // ticks until timer ends
-	done          bool
+	TimerFunction   func()
+	Ticks           int           // ticks until timer ends. ignored if Duration is non-zero.
+	Duration        time.Duration // time until timer ends.
+	Repeat          bool          // if true, the timer starts over each time it fires instead of finishing
+	RunWhileBlocked bool          // if true, scenes keep updating this timer while they are blocked (by blocking animations, etc.)
+
+	elapsedTicks int
+	elapsed      time.Duration
+	paused       bool
+	done         bool
 }
 
-// Process ticks the timer. If the timer's duration has lapsed, does nothing.
+// Process ticks the timer forward one tick, using the current frame delta for timers that count time.
 func (t *Timer) Process() {
-	if t.done {
+	t.Update(GetFrameDelta())
+}
+
+// Update moves the timer forward by one tick, or by delta for timers that count time. If the timer has finished or
+// is paused, does nothing. Repeating timers that count time fire once for each interval that has passed, and carry
+// over any leftover time to the next interval so they do not drift.
+func (t *Timer) Update(delta time.Duration) {
+	if t.done || t.paused {
 		return
 	}
 
-	t.Ticks -= 1
-	if t.Ticks <= 0 {
-		if t.TimerFunction != nil {
-			t.TimerFunction()
+	if t.Duration > 0 {
+		t.elapsed += delta
+		for !t.done && t.elapsed >= t.Duration {
+			t.elapsed -= t.Duration
+			t.fire()
+		}
+	} else {
+		t.elapsedTicks += 1
+		if t.elapsedTicks >= t.Ticks {
+			t.elapsedTicks = 0
+			t.fire()
 		}
+	}
+}
+
+func (t *Timer) fire() {
+	if !t.Repeat {
 		t.done = true
 	}
+
+	if t.TimerFunction != nil {
+		t.TimerFunction()
+	}
+}
+
+// Pause stops the timer from counting until Resume() is called.
+func (t *Timer) Pause() {
+	t.paused = true
+}
+
+// Resume continues a paused timer.
+func (t *Timer) Resume() {
+	t.paused = false
+}
+
+// Cancel stops the timer without running its function. Cancelled timers are done, and will be disposed of by the scene
+// that created them.
+func (t *Timer) Cancel() {
+	t.done = true
+}
+
+// Reset starts the timer's count over from zero. Timers that have finished or been cancelled start running again, so
+// they can be reused. Note that scenes dispose of their timers once they are done, so to restart a timer made with
+// CreateTimer() and friends just make a new one.
+func (t *Timer) Reset() {
+	t.elapsed = 0
+	t.elapsedTicks = 0
+	t.done = false
+}
+
+func (t Timer) IsPaused() bool {
+	return t.paused
 }
 
-// Done reports whether the timer's duration has lapsed.
+// Done reports whether the timer's duration has lapsed, or if it has been cancelled. Repeating timers are only done
+// once cancelled.
 func (t Timer) Done() bool {
 	return t.done
 }
@@ -19,7 +19,9 @@ var (
 	tick                int           //count of number of ticks since engine was initialized
 	frameTargetDuration time.Duration // target duration of each frame, based on user-set framerate
 	prevFrameTime       time.Time     // time we started processing the previous frame. used to calculate frame deltas.
-	currentFrameTime    time.Time     // time we started processing the current frame
+	currentFrameTime    time.Time     // time we started processing the current frame. this is simulated time, see SetFixedFrameDelta()
+	frameStartTime      time.Time     // real time we started processing the current frame. used for framerate limiting.
+	fixedFrameDelta     time.Duration // if non-zero, each frame run by Run() advances the clock by this amount instead of real time.
 
 	overclock          bool          // if true, no framerate limiting is enforced
 	fpsTicks           int           // number of ticks when fps label was last updated
@@ -45,6 +47,14 @@ func SetFramerate(f int) {
 	frameTargetDuration = time.Duration(1000/float64(f)) * time.Millisecond
 }
 
+// SetFixedFrameDelta drives the engine's clock with a fixed, simulated timestep. Each frame run by Run() will advance
+// the clock by delta regardless of how long the frame actually took, so GetFrameDelta() always reports delta. This
+// makes timing deterministic, which is useful for testing and replays. Combined with SetFramerate(0), frames will run
+// as fast as possible, letting the game run faster than real time. Set delta to 0 to go back to using the real clock.
+func SetFixedFrameDelta(delta time.Duration) {
+	fixedFrameDelta = max(delta, 0)
+}
+
 func SetFullScreen(enable bool) {
 	currentPlatform.GetRenderer().SetFullscreen(enable)
 }
@@ -58,6 +68,7 @@ func GetTick() int {
 	return tick
 }
 
+// GetFrameDelta returns the duration of the current frame, capped at 1 second.
 func GetFrameDelta() time.Duration {
 	return min(currentFrameTime.Sub(prevFrameTime), time.Second)
 }
@@ -78,7 +89,7 @@ func isInitialized() bool {
 		return false
 	}
 
-	if currentScene == nil {
+	if len(sceneStack) == 0 {
 		log.Error("Cannot run Tyumi, no initial scene set! Run SetInitialScene() first.")
 		return false
 	}
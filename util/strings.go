@@ -3,11 +3,12 @@ package util
 
 import (
 	"strings"
+	"unicode/utf8"
 )
 
-// WrapText wraps the provided string at WIDTH characters. optionally takes another int, used to determine the maximum
-// number of lines. returns a slice of strings, each element a wrapped line. for words longer than width it just brutally
-// cuts them off. no mercy.
+// WrapText wraps the provided string at WIDTH characters (runes, not bytes). optionally takes another int, used to
+// determine the maximum number of lines. returns a slice of strings, each element a wrapped line. for words longer than
+// width it just brutally cuts them off. no mercy.
 func WrapText(text string, width int, maxlines ...int) (lines []string) {
 	capped := false
 	if len(maxlines) == 1 {
@@ -24,12 +25,12 @@ func WrapText(text string, width int, maxlines ...int) (lines []string) {
 			s = strings.TrimSpace(s) //get rid of nasty tabs and other weird whitespace.
 
 			//super long word make-it-not-break hack.
-			if len(s) > width {
-				s = s[:width]
+			if utf8.RuneCountInString(s) > width {
+				s = string([]rune(s)[:width])
 			}
 
 			//add a line if current word won't fit
-			if len(currentLine)+len(s) > width {
+			if utf8.RuneCountInString(currentLine)+utf8.RuneCountInString(s) > width {
 				currentLine = strings.TrimSpace(currentLine)
 				lines = append(lines, currentLine)
 				currentLine = ""
@@ -41,7 +42,7 @@ func WrapText(text string, width int, maxlines ...int) (lines []string) {
 			}
 
 			currentLine += s
-			if len(currentLine) != width {
+			if utf8.RuneCountInString(currentLine) != width {
 				currentLine += " "
 			}
 		}
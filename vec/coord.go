@@ -2,6 +2,7 @@ package vec
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/bennicholls/tyumi/util"
 )
@@ -84,6 +85,18 @@ func (c1 Coord) ManhattanDistanceTo(c2 Coord) int {
 	return util.Abs(c2.X-c1.X) + util.Abs(c2.Y-c1.Y)
 }
 
+// DirectionTo returns the direction (of the 8 compass directions) that most closely points from c1 to c2. If the
+// coords are the same, returns DIR_NONE.
+func (c1 Coord) DirectionTo(c2 Coord) Direction {
+	if c1 == c2 {
+		return DIR_NONE
+	}
+
+	// y points down, so an angle of 0 is right and angles increase clockwise.
+	octant := int(math.Round(math.Atan2(float64(c2.Y-c1.Y), float64(c2.X-c1.X)) / (math.Pi / 4)))
+	return Direction(util.CycleClamp(octant+int(DIR_RIGHT), 0, 7))
+}
+
 func (c Coord) Lerp(to Coord, val, steps int) Coord {
 	return Coord{
 		X: util.Lerp(c.X, to.X, val, steps),
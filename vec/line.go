@@ -122,6 +122,48 @@ func (l Line) EachCoord() iter.Seq[Coord] {
 	}
 }
 
+// EachCoordSupercover returns an iterator that produces every Coord the line passes through, from start to end
+// inclusive. Unlike EachCoord() (bresenham), this never skips a space the line clips, and if the line passes exactly
+// through a corner both spaces beside the corner are produced. Good for projectiles that shouldn't slip between
+// diagonal walls.
+func (l Line) EachCoordSupercover() iter.Seq[Coord] {
+	dx, dy := util.Abs(l.dx()), util.Abs(l.dy())
+	sx, sy := -1, -1
+	if l.Start.X < l.End.X {
+		sx = 1
+	}
+	if l.Start.Y < l.End.Y {
+		sy = 1
+	}
+
+	return func(yield func(Coord) bool) {
+		c := l.Start
+		e := dx - dy
+		for n := 1 + dx + dy; n > 0; n-- {
+			if !yield(c) {
+				return
+			}
+
+			switch {
+			case e > 0:
+				c.X += sx
+				e -= 2 * dy
+			case e < 0:
+				c.Y += sy
+				e += 2 * dx
+			case n > 1: // passing exactly through a corner
+				if !yield(Coord{c.X + sx, c.Y}) || !yield(Coord{c.X, c.Y + sy}) {
+					return
+				}
+				c.X += sx
+				c.Y += sy
+				e += 2*dx - 2*dy
+				n--
+			}
+		}
+	}
+}
+
 func (l Line) dx() int {
 	return l.End.X - l.Start.X
 }